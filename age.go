@@ -0,0 +1,68 @@
+package razutils
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// matchExt - check if path has one of the extensions (case ignored, with or without the dot). no extensions match all.
+func matchExt(path string, exts []string) bool {
+	if len(exts) == 0 {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range exts {
+		e = strings.ToLower(e)
+		if !strings.HasPrefix(e, ".") {
+			e = "." + e
+		}
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// FilesOlderThan - return the files under dir (recursively) last modified more than d ago.  If extensions are given
+// (e.g. ".log", "tmp") only files with those extensions are returned.
+func FilesOlderThan(dir string, d time.Duration, exts ...string) ([]string, error) {
+	cutoff := time.Now().Add(-d)
+	var files []string
+	err := filepath.WalkDir(LongPath(dir), func(path string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !de.Type().IsRegular() || !matchExt(path, exts) {
+			return nil
+		}
+		info, err := de.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().Before(cutoff) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// CleanOlderThan - delete the files returned by FilesOlderThan, returning the removed files.  on error the files
+// removed so far are returned.
+func CleanOlderThan(dir string, d time.Duration, exts ...string) ([]string, error) {
+	files, err := FilesOlderThan(dir, d, exts...)
+	if err != nil {
+		return nil, err
+	}
+	removed := []string{}
+	for _, f := range files {
+		if err := os.Remove(f); err != nil {
+			return removed, err
+		}
+		removed = append(removed, f)
+	}
+	return removed, nil
+}
@@ -0,0 +1,305 @@
+package razutils
+
+import (
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+/*
+Common parts of the archive helpers (zip, tar...): the options, collecting the files to add, and writing the
+extracted entries with their modes and times.
+*/
+
+// ArchiveOption - an option for the archive create and extract functions
+type ArchiveOption func(*archiveConfig)
+
+type archiveConfig struct {
+	level    int      // compression level of created archives
+	workers  int      // goroutines compressing in parallel
+	lenient  bool     // normalize unsafe entry paths instead of failing
+	match    []string // extract only the files matching one of these globs
+	flatten  bool     // extract the files directly into the destination
+	split    int64    // size of the parts of created archives, 0 for a single file
+	password string   // of encrypted zips
+	progress *progress
+	// paxGlobal gets the records of the tar pax global headers, for the package own metadata (see RestoreIncremental)
+	paxGlobal func(records map[string]string)
+}
+
+func archiveOptions(opts []ArchiveOption) archiveConfig {
+	cfg := archiveConfig{level: flate.DefaultCompression}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return cfg
+}
+
+// WithCompressionLevel - the compression level of created archives, from 0 (store) to 9 (best), -1 is the default
+func WithCompressionLevel(level int) ArchiveOption {
+	return func(c *archiveConfig) { c.level = level }
+}
+
+// WithWorkers - compress with n goroutines where supported (GzipCompress)
+func WithWorkers(n int) ArchiveOption {
+	return func(c *archiveConfig) { c.workers = n }
+}
+
+// WithProgress - call fn as the extraction goes on with the entry being extracted and the progress in bytes: for
+// zip the uncompressed bytes written out of the total size of the entries, for the other formats (streams) the
+// bytes read out of the archive file size.  fn is called often (for every read buffer), it should be quick.
+func WithProgress(fn func(entryName string, done, total int64)) ArchiveOption {
+	return func(c *archiveConfig) {
+		if fn != nil {
+			c.progress = &progress{fn: fn}
+		}
+	}
+}
+
+// progress - the progress state of an extraction. all its methods accept a nil progress (no callback)
+type progress struct {
+	fn    func(entryName string, done, total int64)
+	name  string
+	done  int64
+	total int64
+}
+
+// entry - start a new entry
+func (p *progress) entry(name string) {
+	if p != nil {
+		p.name = name
+		p.fn(p.name, p.done, p.total)
+	}
+}
+
+// setTotal - set the total, the size of the archive for streams
+func (p *progress) setTotal(total int64) {
+	if p != nil {
+		p.total = total
+	}
+}
+
+// reader - wrap r so reading from it advances the progress
+func (p *progress) reader(r io.Reader) io.Reader {
+	if p == nil {
+		return r
+	}
+	return &progressReader{p: p, r: r}
+}
+
+type progressReader struct {
+	p *progress
+	r io.Reader
+}
+
+func (pr *progressReader) Read(b []byte) (int, error) {
+	n, err := pr.r.Read(b)
+	if n > 0 {
+		pr.p.done += int64(n)
+		pr.p.fn(pr.p.name, pr.p.done, pr.p.total)
+	}
+	return n, err
+}
+
+// WithLenientPaths - when extracting, normalize entry paths that are absolute or go out of the destination (zip
+// slip) into it, instead of failing with ErrUnsafePath: "/etc/passwd" and "../../etc/passwd" are both extracted as
+// "etc/passwd".  Symlinks pointing out of the destination are skipped.
+func WithLenientPaths() ArchiveOption {
+	return func(c *archiveConfig) { c.lenient = true }
+}
+
+// WithMatch - extract only the files matching one of the glob patterns (path.Match syntax, case ignored, so "*.srt"
+// matches "A.SRT" as well).  A pattern with no "/"
+// is matched against the file base name ("*.srt"), others against the whole entry path ("subs/*/*.srt").
+// directories and links are not extracted on their own, only as needed for the matching files.
+func WithMatch(patterns ...string) ArchiveOption {
+	return func(c *archiveConfig) { c.match = patterns }
+}
+
+// WithFlatten - extract the files directly into the destination directory, dropping their directories in the
+// archive (a later file with the same name overwrites an earlier one)
+func WithFlatten() ArchiveOption {
+	return func(c *archiveConfig) { c.flatten = true }
+}
+
+// wants - check if the entry is to be extracted, directories and links are only wanted without match and flatten
+func (c *archiveConfig) wants(name string, regular bool) bool {
+	if !regular {
+		return len(c.match) == 0 && !c.flatten
+	}
+	return len(c.match) == 0 || matchGlobs(c.match, name)
+}
+
+// matchGlobs - check if the entry name matches one of the patterns, as described for WithMatch
+func matchGlobs(patterns []string, name string) bool {
+	name = strings.TrimSuffix(strings.ReplaceAll(name, `\`, "/"), "/")
+	for _, p := range patterns {
+		target := name
+		if !strings.Contains(p, "/") {
+			target = path.Base(name)
+		}
+		if ok, _ := path.Match(strings.ToLower(p), strings.ToLower(target)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// target - the path to extract the entry to (see entryPath), flattened if asked
+func (c *archiveConfig) target(destDir, name string) (string, error) {
+	p, err := entryPath(destDir, name, c.lenient)
+	if err != nil || !c.flatten || p == destDir {
+		return p, err
+	}
+	return filepath.Join(destDir, filepath.Base(p)), nil
+}
+
+// ArchiveEntry - an entry listed from an archive
+type ArchiveEntry struct {
+	Name      string // slash separated path in the archive
+	Size      int64  // uncompressed size
+	Mode      fs.FileMode
+	Modified  time.Time
+	Encrypted bool
+}
+
+// ErrUnsafePath - an archive entry whose path would land outside the extraction directory
+var ErrUnsafePath = errors.New("archive entry path escapes the destination directory")
+
+// archiveFile - a file or directory to add to an archive, name is its slash separated path inside the archive
+type archiveFile struct {
+	path string
+	name string
+	info fs.FileInfo
+}
+
+// collectArchiveFiles - list the given files and directories (recursively).  each is stored under its base name, so
+// "/data/subs" adds "subs/..." entries.  symlinks are not followed.
+func collectArchiveFiles(paths []string) ([]archiveFile, error) {
+	var files []archiveFile
+	for _, p := range paths {
+		p = filepath.Clean(p)
+		base := filepath.Dir(p)
+		err := filepath.WalkDir(LongPath(p), func(fp string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(LongPath(base), fp)
+			if err != nil {
+				return err
+			}
+			files = append(files, archiveFile{path: fp, name: filepath.ToSlash(rel), info: info})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// entryPath - the path to extract the entry name to under destDir.  names that are absolute (including windows
+// drive and UNC paths) or would escape destDir with ".." fail with ErrUnsafePath, or with lenient are normalized
+// to stay under destDir.  back slashes are taken as separators, as some windows tools write them.
+func entryPath(destDir, name string, lenient bool) (string, error) {
+	n := strings.ReplaceAll(name, `\`, "/")
+	clean := path.Clean(n)
+	abs := path.IsAbs(n) || (len(n) >= 2 && n[1] == ':')
+	if abs || clean == ".." || strings.HasPrefix(clean, "../") {
+		if !lenient {
+			return "", fmt.Errorf("%w: %s", ErrUnsafePath, name)
+		}
+		if len(n) >= 2 && n[1] == ':' {
+			n = n[2:] // drop the drive
+		}
+		// cleaning a rooted path drops the leading "..", nothing can go above the root
+		clean = strings.TrimPrefix(path.Clean("/"+n), "/")
+	}
+	if clean == "." || clean == "" {
+		return destDir, nil
+	}
+	return filepath.Join(destDir, filepath.FromSlash(clean)), nil
+}
+
+// writeEntryFile - create the file at dest with the content of r, its permissions and mtime (if not zero)
+func writeEntryFile(dest string, r io.Reader, mode fs.FileMode, mtime time.Time) error {
+	if err := os.MkdirAll(LongPath(filepath.Dir(dest)), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(LongPath(dest), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode.Perm()|0o200)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, pausable(r)); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if mode.Perm()&0o200 == 0 {
+		// created writable so it could be written, now set the real mode
+		if err = os.Chmod(LongPath(dest), mode.Perm()); err != nil {
+			return err
+		}
+	}
+	if !mtime.IsZero() {
+		return os.Chtimes(LongPath(dest), mtime, mtime)
+	}
+	return nil
+}
+
+// dirTimes - the mtimes of extracted directories, set after all the entries are written (writing a file into a
+// directory changes its mtime)
+type dirTimes map[string]time.Time
+
+func (dt dirTimes) apply() error {
+	dirs := slices.Sorted(maps.Keys(dt))
+	// deepest first, so a parent is set after its children
+	slices.Reverse(dirs)
+	for _, d := range dirs {
+		if err := os.Chtimes(LongPath(d), dt[d], dt[d]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractSingle - expand the single file compressed source into dest, decompress wraps the source reader
+func extractSingle(source string, dest string, decompress func(r io.Reader) (io.ReadCloser, error), opts []ArchiveOption) error {
+	cfg := archiveOptions(opts)
+	r, err := openArchive(source)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	cfg.progress.setTotal(r.Size())
+	reader, err := decompress(cfg.progress.reader(r))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	fout, err := os.Create(LongPath(dest))
+	if err != nil {
+		return err
+	}
+	defer fout.Close()
+	cfg.progress.entry(filepath.Base(dest))
+	if _, err = io.Copy(fout, pausable(reader)); err != nil {
+		return err
+	}
+	return fout.Close()
+}
@@ -0,0 +1,217 @@
+package razutils
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+/*
+Archiving of aging files (logs, intermediate files): they are gzipped or put together in a tar, and the originals are
+removed only once the archive was read back and its content matched them.
+*/
+
+// tarExts - the names of tar archives, plain or compressed (see compressionFor)
+var tarExts = []string{".tar", ".tar.gz", ".tgz", ".tar.zst", ".tzst", ".tar.xz", ".txz"}
+
+// splitTarExt - split a tar archive name into its stem and its extension, the extension is empty for other names
+func splitTarExt(name string) (stem string, ext string) {
+	lower := strings.ToLower(name)
+	for _, e := range tarExts {
+		if strings.HasSuffix(lower, e) {
+			return name[:len(name)-len(e)], name[len(name)-len(e):]
+		}
+	}
+	return name, ""
+}
+
+// ArchiveOldFiles - archive the files under dir (recursively) last modified more than olderThan ago, and remove them
+// (as FilesOlderThan, exts limits the files to those extensions).  If dest is named as a tar (.tar, .tar.gz, .tgz,
+// .tar.zst, .tar.xz ...) the files are put together in that tar under their path relative to dir.  An existing
+// dest is kept, the new tar is then named with the time ("logs.tar.gz" becomes "logs-20240102-150405.tar.gz").
+// Otherwise dest is a directory and each file is gzipped into it under its path relative to dir ("a/x.log" to
+// "dest/a/x.log.gz").  An original is removed only after the archive is read back and the content matches it, files
+// changed meanwhile are kept.  dest can be inside dir, the archives are not archived again.  The removed files are
+// returned, and the files that failed as FileError values joined in the error.
+func ArchiveOldFiles(dir string, olderThan time.Duration, dest string, exts ...string) ([]string, error) {
+	return archiveOldFiles(context.Background(), dir, olderThan, dest, exts)
+}
+
+// ArchiveOldFilesJob - ArchiveOldFiles as a Scheduler job, e.g. every night at 3:
+//
+//	s.Cron("logs", "0 3 * * *", ArchiveOldFilesJob("/var/log/app", 7*24*time.Hour, "/var/log/app/old", ".log"))
+func ArchiveOldFilesJob(dir string, olderThan time.Duration, dest string, exts ...string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := archiveOldFiles(ctx, dir, olderThan, dest, exts)
+		return err
+	}
+}
+
+func archiveOldFiles(ctx context.Context, dir string, olderThan time.Duration, dest string, exts []string) ([]string, error) {
+	found, err := FilesOlderThan(dir, olderThan, exts...)
+	if err != nil {
+		return nil, err
+	}
+	destAbs := absClean(dest)
+	stem, ext := splitTarExt(destAbs)
+	var files []archiveFile
+	for _, p := range found {
+		a := absClean(p)
+		if ext == "" && IsSubPath(destAbs, a) {
+			continue // in the gzip directory
+		}
+		if ext != "" && strings.HasPrefix(a, stem) && strings.HasSuffix(a, ext) {
+			continue // a tar made by an earlier run
+		}
+		rel, err := filepath.Rel(LongPath(dir), p)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Lstat(p)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, archiveFile{path: p, name: filepath.ToSlash(rel), info: info})
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+	if ext != "" {
+		return archiveOldTar(ctx, files, dest)
+	}
+	return archiveOldGzip(ctx, files, dest)
+}
+
+// archiveOldTar - put the files in a new tar at dest and remove the ones matching their entry
+func archiveOldTar(ctx context.Context, files []archiveFile, dest string) ([]string, error) {
+	if exists, _ := FileExists(dest); exists {
+		stem, ext := splitTarExt(dest)
+		dest = stem + time.Now().Format("-20060102-150405") + ext
+	}
+	if err := tarWrite(dest, files, archiveOptions(nil), nil); err != nil {
+		os.Remove(LongPath(dest))
+		return nil, err
+	}
+	sums, err := tarSums(dest)
+	if err != nil {
+		return nil, fmt.Errorf("verify %s: %w", dest, err)
+	}
+	var removed []string
+	var errs []error
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return removed, errors.Join(append(errs, err)...)
+		}
+		sum, ok := sums[f.name]
+		if !ok {
+			errs = append(errs, FileError{Path: f.path, Err: errors.New("missing from the archive")})
+			continue
+		}
+		if err := removeIfSum(f.path, sum); err != nil {
+			errs = append(errs, FileError{Path: f.path, Err: err})
+			continue
+		}
+		removed = append(removed, f.path)
+	}
+	return removed, errors.Join(errs...)
+}
+
+// tarSums - the xxHash of the content of each regular file entry in the tar at path
+func tarSums(path string) (map[string]uint64, error) {
+	f, err := os.Open(LongPath(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := NewAutoDecompressor(f)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	tr := tar.NewReader(r)
+	sums := map[string]uint64{}
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return sums, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		h := xxhash.New()
+		if _, err = io.Copy(h, pausable(tr)); err != nil {
+			return nil, err
+		}
+		sums[hdr.Name] = h.Sum64()
+	}
+}
+
+// archiveOldGzip - gzip each file under destDir and remove it once the .gz is checked
+func archiveOldGzip(ctx context.Context, files []archiveFile, destDir string) ([]string, error) {
+	var removed []string
+	var errs []error
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return removed, errors.Join(append(errs, err)...)
+		}
+		if err := archiveOneGzip(f, destDir); err != nil {
+			errs = append(errs, FileError{Path: f.path, Err: err})
+			continue
+		}
+		removed = append(removed, f.path)
+	}
+	return removed, errors.Join(errs...)
+}
+
+// archiveOneGzip - gzip the file under destDir with its mtime, read it back and remove the original if it matches
+func archiveOneGzip(f archiveFile, destDir string) error {
+	target := filepath.Join(destDir, filepath.FromSlash(f.name)) + ".gz"
+	if err := os.MkdirAll(LongPath(filepath.Dir(target)), 0o755); err != nil {
+		return err
+	}
+	target, err := NextAvailableName(target)
+	if err != nil {
+		return err
+	}
+	if err = GzipCompress(f.path, target); err != nil {
+		os.Remove(LongPath(target))
+		return err
+	}
+	mtime := f.info.ModTime()
+	if err = os.Chtimes(LongPath(target), mtime, mtime); err != nil {
+		return err
+	}
+	gz, err := os.Open(LongPath(target))
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	h := xxhash.New()
+	if _, err = GunzipCopy(h, pausable(gz)); err != nil {
+		return fmt.Errorf("verify %s: %w", target, err)
+	}
+	return removeIfSum(f.path, h.Sum64())
+}
+
+// removeIfSum - remove the file if its content still has the given xxHash
+func removeIfSum(path string, sum uint64) error {
+	got, err := FileXXHash64(path)
+	if err != nil {
+		return err
+	}
+	if got != sum {
+		return errors.New("changed or damaged in the archive, not removed")
+	}
+	return os.Remove(LongPath(path))
+}
@@ -0,0 +1,89 @@
+package razutils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// BackupsKept - number of backups kept by ReplaceFileWithBackup: dst.bak is the newest, then dst.bak.1, dst.bak.2 ...
+var BackupsKept = 3
+
+// backupName - the name of the n-th backup of path (0 is the newest)
+func backupName(path string, n int) string {
+	if n == 0 {
+		return path + ".bak"
+	}
+	return fmt.Sprintf("%s.bak.%d", path, n)
+}
+
+// ReplaceFileWithBackup - move src over dst, keeping the existing dst as dst.bak.  Older backups are rotated
+// (dst.bak -> dst.bak.1 ...) and the oldest above BackupsKept is removed.  The backup is a hard link to dst (a copy
+// where links are not supported) and src is then renamed over dst, so dst is replaced atomically and always exists.
+// When src is on another volume it is first copied next to dst, and that copy is renamed over dst.
+func ReplaceFileWithBackup(src string, dst string) error {
+	if _, err := os.Stat(LongPath(src)); err != nil {
+		return err
+	}
+	exists, err := FileExists(dst)
+	if err != nil {
+		return err
+	}
+	if exists && BackupsKept > 0 {
+		oldest := backupName(dst, BackupsKept-1)
+		if err := os.Remove(LongPath(oldest)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		for n := BackupsKept - 2; n >= 0; n-- {
+			err := os.Rename(LongPath(backupName(dst, n)), LongPath(backupName(dst, n+1)))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+		bak := backupName(dst, 0)
+		if err := os.Link(LongPath(dst), LongPath(bak)); err != nil {
+			if err := CopyFile(dst, bak); err != nil {
+				return err
+			}
+		}
+	}
+	if err := os.Rename(LongPath(src), LongPath(dst)); err != nil {
+		// most likely a different volume
+		return moveOver(src, dst)
+	}
+	return nil
+}
+
+// moveOver - move src over dst across volumes: src is copied to a temporary file next to dst, renamed over dst and
+// then removed, so dst is never partially written
+func moveOver(src string, dst string) error {
+	in, err := os.Open(LongPath(src))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp, err := os.CreateTemp(LongPath(filepath.Dir(dst)), "."+filepath.Base(dst)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // fails harmlessly once renamed
+	if _, err = io.Copy(tmp, pausable(in)); err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if info, serr := in.Stat(); err == nil && serr == nil {
+		err = os.Chmod(tmp.Name(), info.Mode().Perm())
+	}
+	if err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), LongPath(dst)); err != nil {
+		return err
+	}
+	in.Close()
+	return os.Remove(LongPath(src))
+}
@@ -0,0 +1,51 @@
+package razutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestReplaceFileWithBackup - dst gets the new content and the old one is kept in dst.bak
+func TestReplaceFileWithBackup(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "new"), filepath.Join(dir, "f")
+	os.WriteFile(dst, []byte("old"), 0o644)
+	os.WriteFile(src, []byte("new"), 0o644)
+	if err := ReplaceFileWithBackup(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "new" {
+		t.Fatalf("dst %q", data)
+	}
+	if data, _ := os.ReadFile(dst + ".bak"); string(data) != "old" {
+		t.Fatalf("backup %q", data)
+	}
+	if ok, _ := FileExists(src); ok {
+		t.Fatal("src not moved")
+	}
+}
+
+// TestMoveOver - the cross volume move replaces dst with the content and mode of src and removes src
+func TestMoveOver(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "new"), filepath.Join(dir, "f")
+	os.WriteFile(dst, []byte("old"), 0o644)
+	os.WriteFile(src, []byte("new content"), 0o600)
+	if err := moveOver(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(dst)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatal(info, err)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "new content" {
+		t.Fatalf("dst %q", data)
+	}
+	if ok, _ := FileExists(src); ok {
+		t.Fatal("src not removed")
+	}
+	if m, _ := filepath.Glob(filepath.Join(dir, ".f.tmp*")); len(m) != 0 {
+		t.Fatal("temporary file left", m)
+	}
+}
@@ -0,0 +1,119 @@
+package razutils
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+/*
+Batcher - collect items and hand them to a flush func in batches, e.g. to write scan results to a database in
+bulk.  A batch is flushed once it has maxSize items (by the Add that filled it) or once its first item is maxAge old
+(by a timer goroutine), whichever comes first.  Flushes never run concurrently and keep the items order.
+Close flushes what is left.
+*/
+
+// ErrBatcherClosed - returned by Add after Close
+var ErrBatcherClosed = errors.New("batcher closed")
+
+type Batcher[T any] struct {
+	maxSize int
+	maxAge  time.Duration
+	flush   func([]T) error
+	onError func(error)
+	items   []T
+	batch   int // counts the flushed batches, so a timer of a batch already flushed does nothing
+	timer   *time.Timer
+	closed  bool
+	mu      sync.Mutex // guards the items
+	flushMu sync.Mutex // serializes the flush calls
+}
+
+// NewBatcher - create a batcher flushing batches of up to maxSize items, at most maxAge after their first item was
+// added (0 means only by size)
+func NewBatcher[T any](maxSize int, maxAge time.Duration, flush func([]T) error) *Batcher[T] {
+	return &Batcher[T]{maxSize: max(maxSize, 1), maxAge: maxAge, flush: flush}
+}
+
+// OnError - set a callback for errors of the flushes done by the timer (flushes done by Add, Flush and Close return
+// their errors)
+func (b *Batcher[T]) OnError(fn func(error)) {
+	b.mu.Lock()
+	b.onError = fn
+	b.mu.Unlock()
+}
+
+// Add - add an item, flushing the batch if it is full. returns the flush error
+func (b *Batcher[T]) Add(item T) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrBatcherClosed
+	}
+	b.items = append(b.items, item)
+	if len(b.items) == 1 && b.maxAge > 0 {
+		batch := b.batch
+		b.timer = time.AfterFunc(b.maxAge, func() { b.timerFlush(batch) })
+	}
+	full := len(b.items) >= b.maxSize
+	b.mu.Unlock()
+	if full {
+		return b.Flush()
+	}
+	return nil
+}
+
+// Flush - flush the collected items now
+func (b *Batcher[T]) Flush() error {
+	return b.flushBatch(-1)
+}
+
+// flushBatch - flush the collected items if they are the given batch (any batch for -1)
+func (b *Batcher[T]) flushBatch(batch int) error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+	b.mu.Lock()
+	if batch >= 0 && batch != b.batch {
+		// a timer that fired while its batch was being flushed by Add or Flush
+		b.mu.Unlock()
+		return nil
+	}
+	items := b.items
+	b.items = nil
+	b.batch++
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.mu.Unlock()
+	if len(items) == 0 {
+		return nil
+	}
+	return safeRun(func() error { return b.flush(items) })
+}
+
+func (b *Batcher[T]) timerFlush(batch int) {
+	if err := b.flushBatch(batch); err != nil {
+		b.mu.Lock()
+		fn := b.onError
+		b.mu.Unlock()
+		if fn != nil {
+			fn(err)
+		}
+	}
+}
+
+// Len - return the number of items waiting for the next flush
+func (b *Batcher[T]) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.items)
+}
+
+// Close - stop accepting items and flush the remaining ones
+func (b *Batcher[T]) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	return b.Flush()
+}
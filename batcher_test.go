@@ -0,0 +1,28 @@
+package razutils
+
+import (
+	"testing"
+	"time"
+)
+
+// TestBatcherStaleTimer - a timer of a batch that was already flushed does not flush the next batch early
+func TestBatcherStaleTimer(t *testing.T) {
+	var flushed [][]int
+	b := NewBatcher(10, time.Hour, func(items []int) error {
+		flushed = append(flushed, items)
+		return nil
+	})
+	b.Add(1)
+	if err := b.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	b.Add(2)
+	b.timerFlush(0) // fired for the first batch while it was being flushed
+	if b.Len() != 1 || len(flushed) != 1 {
+		t.Fatalf("the next batch was flushed by an old timer: %v", flushed)
+	}
+	b.timerFlush(1)
+	if b.Len() != 0 || len(flushed) != 2 {
+		t.Fatalf("the batch timer did not flush: %v", flushed)
+	}
+}
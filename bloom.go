@@ -0,0 +1,165 @@
+package razutils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+/*
+BloomFilter - a space efficient probabilistic set: MaybeContains never misses an added item, but may wrongly report
+an item that was not added, at about the false positive rate the filter was created for.  Use it as a fast pre-check
+before an expensive exact lookup (e.g. before PushUnique on a huge queue).  It is thread safe and can be saved to
+and loaded from disk.
+*/
+
+// bloomMagic - file header of a saved filter
+const bloomMagic = "RZBF1"
+
+// bloomMaxK - the most hash functions, more are only needed for absurdly low false positive rates
+const bloomMaxK = 64
+
+// bloomReadChunk - the words read at once by ReadBloomFilter, the bits are not allocated before they are read
+const bloomReadChunk = 1 << 16
+
+type BloomFilter struct {
+	bits  []uint64
+	m     uint64 // number of bits
+	k     uint64 // number of hash functions
+	count uint64 // items added
+	mu    sync.RWMutex
+}
+
+// NewBloomFilter - create a filter sized for expectedItems with the wanted false positive rate (e.g. 0.01)
+func NewBloomFilter(expectedItems int, fpRate float64) *BloomFilter {
+	if expectedItems < 1 {
+		expectedItems = 1
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = 0.01
+	}
+	n := float64(expectedItems)
+	m := uint64(math.Ceil(-n * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	m = (m + 63) &^ 63 // whole words
+	k := uint64(math.Min(bloomMaxK, math.Max(1, math.Round(float64(m)/n*math.Ln2))))
+	return &BloomFilter{bits: make([]uint64, m/64), m: m, k: k}
+}
+
+// positions - the k bit positions of data, by double hashing of a single 64 bit hash
+func (b *BloomFilter) positions(data []byte, fn func(pos uint64) bool) {
+	h := xxhash.Sum64(data)
+	h1, h2 := h&0xFFFFFFFF, h>>32|1
+	for i := uint64(0); i < b.k; i++ {
+		if !fn((h1 + i*h2) % b.m) {
+			return
+		}
+	}
+}
+
+// Add - add an item
+func (b *BloomFilter) Add(data []byte) {
+	b.mu.Lock()
+	b.positions(data, func(pos uint64) bool {
+		b.bits[pos/64] |= 1 << (pos % 64)
+		return true
+	})
+	b.count++
+	b.mu.Unlock()
+}
+
+// AddString - add a string item
+func (b *BloomFilter) AddString(s string) {
+	b.Add([]byte(s))
+}
+
+// MaybeContains - false means the item was surely never added, true means it probably was
+func (b *BloomFilter) MaybeContains(data []byte) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	found := true
+	b.positions(data, func(pos uint64) bool {
+		found = b.bits[pos/64]&(1<<(pos%64)) != 0
+		return found
+	})
+	return found
+}
+
+// MaybeContainsString - MaybeContains for a string item
+func (b *BloomFilter) MaybeContainsString(s string) bool {
+	return b.MaybeContains([]byte(s))
+}
+
+// Count - return the number of items added
+func (b *BloomFilter) Count() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return int(b.count)
+}
+
+// WriteTo - serialize the filter into w
+func (b *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var buf bytes.Buffer
+	buf.WriteString(bloomMagic)
+	for _, v := range []uint64{b.m, b.k, b.count} {
+		binary.Write(&buf, binary.LittleEndian, v)
+	}
+	binary.Write(&buf, binary.LittleEndian, b.bits)
+	return buf.WriteTo(w)
+}
+
+// Save - save the filter into a file (atomically)
+func (b *BloomFilter) Save(path string) error {
+	var buf bytes.Buffer
+	if _, err := b.WriteTo(&buf); err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, buf.Bytes(), 0644)
+}
+
+// ReadBloomFilter - read a filter serialized by WriteTo
+func ReadBloomFilter(r io.Reader) (*BloomFilter, error) {
+	magic := make([]byte, len(bloomMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, err
+	}
+	if string(magic) != bloomMagic {
+		return nil, errors.New("not a bloom filter file")
+	}
+	b := &BloomFilter{}
+	for _, v := range []*uint64{&b.m, &b.k, &b.count} {
+		if err := binary.Read(r, binary.LittleEndian, v); err != nil {
+			return nil, err
+		}
+	}
+	if b.m == 0 || b.m%64 != 0 || b.k == 0 || b.k > bloomMaxK || b.m > 1<<40 {
+		return nil, errors.New("corrupted bloom filter file")
+	}
+	// read in chunks, so a corrupted size fails at the end of the data rather than allocating it all
+	words := int(b.m / 64)
+	for len(b.bits) < words {
+		chunk := make([]uint64, min(words-len(b.bits), bloomReadChunk))
+		if err := binary.Read(r, binary.LittleEndian, chunk); err != nil {
+			return nil, err
+		}
+		b.bits = append(b.bits, chunk...)
+	}
+	return b, nil
+}
+
+// LoadBloomFilter - load a filter saved by Save
+func LoadBloomFilter(path string) (*BloomFilter, error) {
+	f, err := os.Open(LongPath(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadBloomFilter(f)
+}
@@ -0,0 +1,31 @@
+package razutils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// TestReadBloomFilterCorrupted - headers with a huge size or number of hashes are refused without allocating them
+func TestReadBloomFilterCorrupted(t *testing.T) {
+	header := func(m, k uint64) []byte {
+		var buf bytes.Buffer
+		buf.WriteString(bloomMagic)
+		for _, v := range []uint64{m, k, 0} {
+			binary.Write(&buf, binary.LittleEndian, v)
+		}
+		buf.Write(make([]byte, 64))
+		return buf.Bytes()
+	}
+	for _, h := range [][]byte{header(1<<40, 3), header(64, 1<<40), header(64, bloomMaxK+1)} {
+		if _, err := ReadBloomFilter(bytes.NewReader(h)); err == nil {
+			t.Fatal("corrupted header accepted")
+		}
+	}
+	if _, err := ReadBloomFilter(bytes.NewReader(header(512, 3))); err != nil {
+		t.Fatal(err)
+	}
+	if b := NewBloomFilter(10, 1e-30); b.k > bloomMaxK {
+		t.Fatalf("k = %d", b.k)
+	}
+}
@@ -0,0 +1,83 @@
+package razutils
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrQueueFull - returned by TryPush when a bounded queue is at its capacity
+var ErrQueueFull = errors.New("queue full")
+
+/*
+BoundedQueue - a capacity limited FIFO queue giving back pressure between producers and consumers:
+Push blocks while the queue is full and Pop blocks until an item arrives, both can be cancelled by a context.
+TryPush/TryPop are the non-blocking versions.  It is safe for use by multiple goroutines.
+*/
+type BoundedQueue[T any] struct {
+	ch chan T
+}
+
+// NewBoundedQueue - create a queue holding up to capacity items (at least 1)
+func NewBoundedQueue[T any](capacity int) *BoundedQueue[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &BoundedQueue[T]{ch: make(chan T, capacity)}
+}
+
+// Push - add an item, blocking while the queue is full. returns the context error if it is done first.
+func (q *BoundedQueue[T]) Push(ctx context.Context, item T) error {
+	select {
+	case q.ch <- item:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TryPush - add an item if there is room, ErrQueueFull otherwise
+func (q *BoundedQueue[T]) TryPush(item T) error {
+	select {
+	case q.ch <- item:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Pop - remove and return the oldest item, blocking until one is available or the context is done
+func (q *BoundedQueue[T]) Pop(ctx context.Context) (T, error) {
+	select {
+	case item := <-q.ch:
+		return item, nil
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// TryPop - remove and return the oldest item, ErrQueueEmpty if there is none
+func (q *BoundedQueue[T]) TryPop() (T, error) {
+	select {
+	case item := <-q.ch:
+		return item, nil
+	default:
+		var zero T
+		return zero, ErrQueueEmpty
+	}
+}
+
+// Len - return the number of items in the queue
+func (q *BoundedQueue[T]) Len() int {
+	return len(q.ch)
+}
+
+// Cap - return the queue capacity
+func (q *BoundedQueue[T]) Cap() int {
+	return cap(q.ch)
+}
+
+// IsFull - check if the queue is at capacity
+func (q *BoundedQueue[T]) IsFull() bool {
+	return len(q.ch) == cap(q.ch)
+}
@@ -0,0 +1,174 @@
+package razutils
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+/*
+CircuitBreaker - stop calling a failing service for a while instead of hammering it.
+Closed: calls go through, after threshold consecutive failures the breaker opens.
+Open: calls fail at once with ErrCircuitOpen until the cooldown passes, then it is half-open.
+Half-open: a single trial call goes through, success closes the breaker, failure opens it again for another cooldown.
+*/
+
+// ErrCircuitOpen - returned by the breaker instead of calling the function while open
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// BreakerState - the state of a CircuitBreaker
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	state     BreakerState
+	failures  int       // consecutive failures while closed
+	openedAt  time.Time // when the breaker last opened
+	trial     bool      // a half-open trial call is running
+	onChange  func(from, to BreakerState)
+	mu        sync.Mutex
+}
+
+// NewCircuitBreaker - create a closed breaker opening after threshold consecutive failures for cooldown
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{threshold: max(threshold, 1), cooldown: cooldown}
+}
+
+// OnStateChange - set a callback for state changes (e.g. for logging). it is called under the breaker lock, so it
+// must not call the breaker.
+func (b *CircuitBreaker) OnStateChange(fn func(from, to BreakerState)) {
+	b.mu.Lock()
+	b.onChange = fn
+	b.mu.Unlock()
+}
+
+// setStateLocked - change the state and notify (must be called under lock)
+func (b *CircuitBreaker) setStateLocked(s BreakerState) {
+	if s == b.state {
+		return
+	}
+	from := b.state
+	b.state = s
+	switch s {
+	case BreakerOpen:
+		b.openedAt = time.Now()
+	case BreakerClosed:
+		b.failures = 0
+	}
+	if b.onChange != nil {
+		b.onChange(from, s)
+	}
+}
+
+// stateLocked - return the state, moving an open breaker whose cooldown passed to half-open (must be called under lock)
+func (b *CircuitBreaker) stateLocked() BreakerState {
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.cooldown {
+		b.setStateLocked(BreakerHalfOpen)
+	}
+	return b.state
+}
+
+// State - return the current state
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stateLocked()
+}
+
+// allow - check if a call may go through now, marking the half-open trial as running
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.stateLocked() {
+	case BreakerOpen:
+		return false
+	case BreakerHalfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+	}
+	return true
+}
+
+// done - record the result of a call that was allowed
+func (b *CircuitBreaker) done(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	trial := b.trial
+	b.trial = false
+	if err == nil {
+		b.failures = 0
+		if trial {
+			b.setStateLocked(BreakerClosed)
+		}
+		return
+	}
+	if trial {
+		b.setStateLocked(BreakerOpen)
+		return
+	}
+	b.failures++
+	if b.state == BreakerClosed && b.failures >= b.threshold {
+		b.setStateLocked(BreakerOpen)
+	}
+}
+
+// Do - call fn through the breaker.  returns ErrCircuitOpen without calling fn if the breaker is open.  context
+// errors (the caller gave up) are not counted as failures.
+func (b *CircuitBreaker) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !b.allow() {
+		return ErrCircuitOpen
+	}
+	err := fn(ctx)
+	if err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		b.mu.Lock()
+		b.trial = false
+		b.mu.Unlock()
+		return err
+	}
+	b.done(err)
+	return err
+}
+
+// Reset - close the breaker and clear the failures count
+func (b *CircuitBreaker) Reset() {
+	b.mu.Lock()
+	b.trial = false
+	b.setStateLocked(BreakerClosed)
+	b.failures = 0
+	b.mu.Unlock()
+}
+
+// Breaker - wrap fn so every call goes through the breaker
+func Breaker[A, R any](b *CircuitBreaker, fn func(ctx context.Context, arg A) (R, error)) func(ctx context.Context, arg A) (R, error) {
+	return func(ctx context.Context, arg A) (R, error) {
+		var res R
+		err := b.Do(ctx, func(ctx context.Context) error {
+			var err error
+			res, err = fn(ctx, arg)
+			return err
+		})
+		return res, err
+	}
+}
@@ -0,0 +1,106 @@
+package razutils
+
+import (
+	"sync"
+)
+
+/*
+Broadcaster - in memory pub/sub: every published value is delivered to all the current subscribers.
+Each subscriber has a bounded buffer, when a slow subscriber's buffer is full the oldest value in it is dropped to
+make room, so Publish never blocks.
+*/
+
+type subscriber[T any] struct {
+	ch      chan T
+	dropped int
+}
+
+type Broadcaster[T any] struct {
+	buffer int
+	subs   map[*subscriber[T]]struct{}
+	closed bool
+	mu     sync.Mutex
+}
+
+// NewBroadcaster - create a broadcaster with a buffer of size buffer (at least 1) per subscriber
+func NewBroadcaster[T any](buffer int) *Broadcaster[T] {
+	return &Broadcaster[T]{buffer: max(buffer, 1), subs: make(map[*subscriber[T]]struct{})}
+}
+
+// Subscribe - return a channel receiving the values published from now on, and a cancel func that unsubscribes and
+// closes the channel.  on a closed broadcaster the channel is returned closed.
+func (b *Broadcaster[T]) Subscribe() (<-chan T, func()) {
+	s := &subscriber[T]{ch: make(chan T, b.buffer)}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(s.ch)
+		return s.ch, func() {}
+	}
+	b.subs[s] = struct{}{}
+	return s.ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[s]; ok {
+			delete(b.subs, s)
+			close(s.ch)
+		}
+	}
+}
+
+// Publish - send v to all the subscribers, dropping their oldest buffered value if needed. does nothing once closed
+func (b *Broadcaster[T]) Publish(v T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	for s := range b.subs {
+		for {
+			select {
+			case s.ch <- v:
+			default:
+				// full, drop the oldest (unless the subscriber just took it) and try again
+				select {
+				case <-s.ch:
+					s.dropped++
+				default:
+				}
+				continue
+			}
+			break
+		}
+	}
+}
+
+// Subscribers - return the number of current subscribers
+func (b *Broadcaster[T]) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// Dropped - return the total number of values dropped for the current subscribers
+func (b *Broadcaster[T]) Dropped() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for s := range b.subs {
+		n += s.dropped
+	}
+	return n
+}
+
+// Close - close all the subscriber channels, later publishes are ignored
+func (b *Broadcaster[T]) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for s := range b.subs {
+		close(s.ch)
+	}
+	clear(b.subs)
+}
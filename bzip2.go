@@ -0,0 +1,14 @@
+package razutils
+
+import (
+	"compress/bzip2"
+	"io"
+)
+
+// Bzip2Extract - expand a .bz2 file into the original file. Source is the bz2 file path, dest is what the result
+// filename should be.  (there is no Bzip2Compress, the standard library only decodes bzip2)
+func Bzip2Extract(source string, dest string, opts ...ArchiveOption) error {
+	return extractSingle(source, dest, func(r io.Reader) (io.ReadCloser, error) {
+		return io.NopCloser(bzip2.NewReader(r)), nil
+	}, opts)
+}
@@ -0,0 +1,140 @@
+package razutils
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Charset names returned by ReadFileUTF8
+const (
+	CharsetUTF8        = "utf-8"
+	CharsetUTF16LE     = "utf-16le"
+	CharsetUTF16BE     = "utf-16be"
+	CharsetWindows1252 = "windows-1252"
+	CharsetWindows1255 = "windows-1255"
+	CharsetISO88591    = "iso-8859-1"
+)
+
+// decodeText - convert raw file content into an utf-8 string, handling BOMs and detecting UTF-16 (with or without BOM).
+// content that is not valid utf-8 is guessed to be Hebrew (Windows-1255) or Latin (Windows-1252 / ISO-8859-1).
+// returns the text and the detected charset.
+func decodeText(data []byte) (string, string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return string(data[3:]), CharsetUTF8, nil
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		b, err := unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder().Bytes(data)
+		return string(b), CharsetUTF16LE, err
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		b, err := unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM).NewDecoder().Bytes(data)
+		return string(b), CharsetUTF16BE, err
+	}
+	if le, be := looksUTF16(data); le || be {
+		endian, cs := unicode.LittleEndian, CharsetUTF16LE
+		if be {
+			endian, cs = unicode.BigEndian, CharsetUTF16BE
+		}
+		b, err := unicode.UTF16(endian, unicode.IgnoreBOM).NewDecoder().Bytes(data)
+		return string(b), cs, err
+	}
+	if utf8.Valid(data) {
+		return string(data), CharsetUTF8, nil
+	}
+	cs := guessSingleByte(data)
+	var cm *charmap.Charmap
+	switch cs {
+	case CharsetWindows1255:
+		cm = charmap.Windows1255
+	case CharsetWindows1252:
+		cm = charmap.Windows1252
+	default:
+		cm = charmap.ISO8859_1
+	}
+	b, err := cm.NewDecoder().Bytes(data)
+	return string(b), cs, err
+}
+
+// looksUTF16 - guess if BOM-less content is UTF-16 by the zero bytes latin text has in every other position
+func looksUTF16(data []byte) (le bool, be bool) {
+	n := len(data) &^ 1
+	if n < 4 {
+		return false, false
+	}
+	if n > 4096 {
+		n = 4096
+	}
+	var even, odd int
+	for i := 0; i < n; i += 2 {
+		if data[i] == 0 {
+			even++
+		}
+		if data[i+1] == 0 {
+			odd++
+		}
+	}
+	half := n / 2
+	return odd > half*4/10 && even == 0, even > half*4/10 && odd == 0
+}
+
+// guessSingleByte - guess the 8 bit charset. Hebrew words are made entirely of high bytes (0xE0-0xFA in
+// Windows-1255), while accented Latin letters mostly appear alone between ascii letters.
+func guessSingleByte(data []byte) string {
+	var high, paired, hebrew, c1 int
+	for i, b := range data {
+		if b < 0x80 {
+			continue
+		}
+		high++
+		if b >= 0xE0 && b <= 0xFA {
+			hebrew++
+		}
+		if b < 0xA0 {
+			c1++ // 0x80-0x9F are printable in windows code pages only
+		}
+		if (i > 0 && data[i-1] >= 0x80) || (i+1 < len(data) && data[i+1] >= 0x80) {
+			paired++
+		}
+	}
+	switch {
+	case high > 0 && paired*2 > high && hebrew*4 > high*3:
+		return CharsetWindows1255
+	case c1 > 0:
+		return CharsetWindows1252
+	}
+	return CharsetISO88591
+}
+
+// ReadFileUTF8 - read a text file into an utf-8 string, stripping BOMs and transcoding UTF-16LE/BE, Windows-1255
+// (Hebrew) and Windows-1252 / ISO-8859-1 (Latin) content.  The detected charset is returned as well.
+// Single byte charsets can not be detected reliably, use ReadFileCharset when the charset is known.
+func ReadFileUTF8(path string) (string, string, error) {
+	data, err := os.ReadFile(LongPath(path))
+	if err != nil {
+		return "", "", err
+	}
+	return decodeText(data)
+}
+
+// ReadFileCharset - read a text file in a known charset (any WHATWG name/label: "windows-1255", "iso-8859-8",
+// "utf-16le" etc.) into an utf-8 string. a leading utf-8 BOM is removed.
+func ReadFileCharset(path string, charset string) (string, error) {
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return "", fmt.Errorf("unknown charset %q: %w", charset, err)
+	}
+	data, err := os.ReadFile(LongPath(path))
+	if err != nil {
+		return "", err
+	}
+	b, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimPrefix(b, []byte{0xEF, 0xBB, 0xBF})), nil
+}
@@ -0,0 +1,57 @@
+package razutils
+
+import (
+	"errors"
+	"sync"
+)
+
+/*
+Collect - run different tasks returning the same result type and gather all their results, a lighter sibling of
+ParallelMap when the tasks are not a map over a slice:
+
+	var c Collect[int64]
+	c.Go(func() (int64, error) { return dirSize(a) })
+	c.Go(func() (int64, error) { return dirSize(b) })
+	sizes, err := c.Wait()
+
+The zero value is ready to use.  Results are in the order the tasks were started, a failed task leaves the zero value
+in its slot.  A panic in a task is returned as a *PanicError.
+*/
+
+type Collect[T any] struct {
+	results []T
+	errs    []error
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+}
+
+// Go - start a task in a new goroutine
+func (c *Collect[T]) Go(fn func() (T, error)) {
+	c.mu.Lock()
+	i := len(c.results)
+	var zero T
+	c.results = append(c.results, zero)
+	c.errs = append(c.errs, nil)
+	c.mu.Unlock()
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		var v T
+		err := safeRun(func() error {
+			var err error
+			v, err = fn()
+			return err
+		})
+		c.mu.Lock()
+		c.results[i], c.errs[i] = v, err
+		c.mu.Unlock()
+	}()
+}
+
+// Wait - wait for all the tasks started so far and return their results with the errors joined
+func (c *Collect[T]) Wait() ([]T, error) {
+	c.wg.Wait()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.results, errors.Join(c.errs...)
+}
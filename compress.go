@@ -0,0 +1,115 @@
+package razutils
+
+import (
+	"bufio"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+/*
+The compression layers, on streams so they work for files, network bodies and pipes alike: the compressor of a type
+(picked for created archives by their extension), and the decompressor of a read stream by its magic bytes (so
+mislabeled files work).
+*/
+
+// ErrUnsupportedCompression - NewCompressor was asked for a type it can not write
+var ErrUnsupportedCompression = errors.New("unsupported compression type")
+
+// nopWriteCloser - a WriteCloser for streams without compression
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// NewCompressor - wrap w with a compressor of type t (FileTypeGzip, FileTypeZstd or FileTypeXz), WithCompressionLevel
+// sets the level (xz has a single one).  Close flushes the compressed stream but does not close w.  Other types
+// fail with ErrUnsupportedCompression (bzip2 can only be read).
+func NewCompressor(w io.Writer, t FileType, opts ...ArchiveOption) (io.WriteCloser, error) {
+	cfg := archiveOptions(opts)
+	switch t {
+	case FileTypeGzip:
+		return gzip.NewWriterLevel(w, cfg.level)
+	case FileTypeZstd:
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstdLevel(cfg.level)))
+	case FileTypeXz:
+		return xz.NewWriter(w)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedCompression, t)
+}
+
+// compressionFor - the compression of a created file by its extension (.gz, .tgz, .zst, .tzst, .xz, .txz), unknown
+// for none
+func compressionFor(name string) FileType {
+	switch ext := strings.ToLower(filepath.Ext(name)); ext {
+	case ".gz", ".tgz":
+		return FileTypeGzip
+	case ".zst", ".tzst":
+		return FileTypeZstd
+	case ".xz", ".txz":
+		return FileTypeXz
+	}
+	return FileTypeUnknown
+}
+
+// compressWriterFor - wrap w with the compression matching the extension of name (see compressionFor), or with nothing
+func compressWriterFor(name string, w io.Writer, level int) (io.WriteCloser, error) {
+	t := compressionFor(name)
+	if t == FileTypeUnknown {
+		return nopWriteCloser{w}, nil
+	}
+	return NewCompressor(w, t, WithCompressionLevel(level))
+}
+
+// NewAutoDecompressor - return a reader of the decompressed content of r, detecting the compression (gzip, zstd, xz
+// or bzip2) by its magic bytes, e.g. for an HTTP response body or a file of any of them.  Content not compressed, or
+// in an unknown compression, is returned as is.  Close releases the decompressor only, r is not closed.
+func NewAutoDecompressor(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReaderSize(r, sniffLen)
+	h, _ := br.Peek(sniffLen) // a short stream is fine, the error comes again on read
+	switch detectType(h) {
+	case FileTypeGzip:
+		gz, err := newGzipReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return gz, nil
+	case FileTypeZstd:
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	case FileTypeXz:
+		xr, err := xz.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(xr), nil
+	case FileTypeBzip2:
+		return io.NopCloser(bzip2.NewReader(br)), nil
+	default:
+		return io.NopCloser(br), nil
+	}
+}
+
+// zstdLevel - map a gzip style level (-1 default, 0-9) to a zstd encoder level
+func zstdLevel(level int) zstd.EncoderLevel {
+	switch {
+	case level < 0:
+		return zstd.SpeedDefault
+	case level <= 1:
+		return zstd.SpeedFastest
+	case level <= 5:
+		return zstd.SpeedDefault
+	case level <= 7:
+		return zstd.SpeedBetterCompression
+	}
+	return zstd.SpeedBestCompression
+}
@@ -0,0 +1,113 @@
+package razutils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+/*
+In memory compression helpers, working on []byte and on io.Reader/io.Writer instead of file paths, e.g. to compress
+a payload before uploading it or to store compressed values in a cache.
+*/
+
+// GzipCopy - gzip everything read from src into dst, returning the number of uncompressed bytes
+func GzipCopy(dst io.Writer, src io.Reader) (int64, error) {
+	w := gzip.NewWriter(dst)
+	n, err := io.Copy(w, src)
+	if err != nil {
+		return n, err
+	}
+	return n, w.Close()
+}
+
+// GunzipCopy - decompress the gzip stream src into dst (all the members of concatenated streams), returning the
+// number of decompressed bytes
+func GunzipCopy(dst io.Writer, src io.Reader) (int64, error) {
+	r, err := newGzipReader(src)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	return io.Copy(dst, r)
+}
+
+// DecompressCopy - decompress src into dst, the compression (gzip, zstd, xz or bzip2) is detected as by
+// NewAutoDecompressor and content not compressed is copied as is.  The number of decompressed bytes is returned.
+func DecompressCopy(dst io.Writer, src io.Reader) (int64, error) {
+	r, err := NewAutoDecompressor(src)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	return io.Copy(dst, r)
+}
+
+// GzipBytes - return data gzipped
+func GzipBytes(data []byte) ([]byte, error) {
+	var b bytes.Buffer
+	if _, err := GzipCopy(&b, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// GunzipBytes - return the decompressed content of gzipped data
+func GunzipBytes(data []byte) ([]byte, error) {
+	var b bytes.Buffer
+	if _, err := GunzipCopy(&b, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// ZstdCopy - compress everything read from src into dst with zstd, returning the number of uncompressed bytes
+func ZstdCopy(dst io.Writer, src io.Reader) (int64, error) {
+	w, err := zstd.NewWriter(dst)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(w, src)
+	if err != nil {
+		w.Close()
+		return n, err
+	}
+	return n, w.Close()
+}
+
+// UnzstdCopy - decompress the zstd stream src into dst, returning the number of decompressed bytes
+func UnzstdCopy(dst io.Writer, src io.Reader) (int64, error) {
+	r, err := zstd.NewReader(src)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	return io.Copy(dst, r)
+}
+
+// the encoder and decoder for the []byte zstd helpers, they are safe for concurrent EncodeAll/DecodeAll calls and
+// costly to create, so they are shared
+var (
+	zstdEncoder = NewLazyValue(func() (*zstd.Encoder, error) { return zstd.NewWriter(nil) })
+	zstdDecoder = NewLazyValue(func() (*zstd.Decoder, error) { return zstd.NewReader(nil) })
+)
+
+// ZstdBytes - return data compressed with zstd
+func ZstdBytes(data []byte) ([]byte, error) {
+	enc, err := zstdEncoder.Get()
+	if err != nil {
+		return nil, err
+	}
+	return enc.EncodeAll(data, nil), nil
+}
+
+// UnzstdBytes - return the decompressed content of zstd data
+func UnzstdBytes(data []byte) ([]byte, error) {
+	dec, err := zstdDecoder.Get()
+	if err != nil {
+		return nil, err
+	}
+	return dec.DecodeAll(data, nil)
+}
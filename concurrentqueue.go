@@ -0,0 +1,71 @@
+package razutils
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+/*
+ConcurrentQueue - an unbounded FIFO queue for many producers and consumers, using the two-lock algorithm of
+Michael & Scott: pushes lock only the tail and pops lock only the head, so producers and consumers do not block each
+other (the single mutex of Queue serializes them all).  It has only the basic queue operations; use Queue/TypedQueue
+when the richer API is needed.
+*/
+
+type cqNode[T any] struct {
+	value T
+	next  atomic.Pointer[cqNode[T]]
+}
+
+type ConcurrentQueue[T any] struct {
+	head   *cqNode[T] // dummy node, head.next is the oldest item
+	tail   *cqNode[T]
+	headMu sync.Mutex
+	tailMu sync.Mutex
+	length atomic.Int64
+}
+
+// NewConcurrentQueue - create an empty queue
+func NewConcurrentQueue[T any]() *ConcurrentQueue[T] {
+	dummy := &cqNode[T]{}
+	return &ConcurrentQueue[T]{head: dummy, tail: dummy}
+}
+
+// Push - add an item at the tail
+func (q *ConcurrentQueue[T]) Push(item T) {
+	n := &cqNode[T]{value: item}
+	q.tailMu.Lock()
+	q.tail.next.Store(n)
+	q.tail = n
+	q.tailMu.Unlock()
+	q.length.Add(1)
+}
+
+// Pop - remove and return the oldest item. Error is returned if the queue is empty
+func (q *ConcurrentQueue[T]) Pop() (T, error) {
+	var zero T
+	q.headMu.Lock()
+	next := q.head.next.Load()
+	if next == nil {
+		q.headMu.Unlock()
+		return zero, ErrQueueEmpty
+	}
+	item := next.value
+	next.value = zero // next becomes the dummy node, do not keep the item alive
+	q.head = next
+	q.headMu.Unlock()
+	q.length.Add(-1)
+	return item, nil
+}
+
+// Len - return the number of items. with concurrent pushes and pops it is only a momentary estimate
+func (q *ConcurrentQueue[T]) Len() int {
+	return int(max(q.length.Load(), 0))
+}
+
+// IsEmpty - check if the queue is empty
+func (q *ConcurrentQueue[T]) IsEmpty() bool {
+	q.headMu.Lock()
+	defer q.headMu.Unlock()
+	return q.head.next.Load() == nil
+}
@@ -0,0 +1,51 @@
+package razutils
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// benchMPMC - move b.N items from the producers to the consumers through push/pop
+func benchMPMC(b *testing.B, producers, consumers int, push func(int), pop func() bool) {
+	var wg sync.WaitGroup
+	var taken atomic.Int64
+	total := int64(b.N)
+	b.ResetTimer()
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := p; i < b.N; i += producers {
+				push(i)
+			}
+		}(p)
+	}
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for taken.Load() < total {
+				if pop() {
+					taken.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// BenchmarkConcurrentQueueMPMC - ConcurrentQueue against the single mutex Queue with several producers and consumers
+func BenchmarkConcurrentQueueMPMC(b *testing.B) {
+	for _, n := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("ConcurrentQueue/%dx%d", n, n), func(b *testing.B) {
+			q := NewConcurrentQueue[int]()
+			benchMPMC(b, n, n, q.Push, func() bool { _, err := q.Pop(); return err == nil })
+		})
+		b.Run(fmt.Sprintf("Queue/%dx%d", n, n), func(b *testing.B) {
+			q := MakeTypedQueue[int](0)
+			benchMPMC(b, n, n, q.Push, func() bool { _, err := q.Pop(); return err == nil })
+		})
+	}
+}
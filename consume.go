@@ -0,0 +1,85 @@
+package razutils
+
+import (
+	"context"
+	"fmt"
+)
+
+// ConsumeOption - an option for ConsumeQueue
+type ConsumeOption func(*consumeConfig)
+
+type consumeConfig struct {
+	rate    float64
+	noDrain bool
+	onError func(error)
+}
+
+// ConsumeRate - handle at most perSecond items per second (over all the workers)
+func ConsumeRate(perSecond float64) ConsumeOption {
+	return func(c *consumeConfig) { c.rate = perSecond }
+}
+
+// ConsumeNoDrain - return at once when ctx is done, leaving the queued items in the queue (an item a worker already
+// took is still handled)
+func ConsumeNoDrain() ConsumeOption {
+	return func(c *consumeConfig) { c.noDrain = true }
+}
+
+// ConsumeOnError - call fn with the handler errors (wrapped with the item). by default they are ignored
+func ConsumeOnError(fn func(err error)) ConsumeOption {
+	return func(c *consumeConfig) { c.onError = fn }
+}
+
+// ConsumeQueue - handle the items of q with workers goroutines, waiting for new items as they are pushed, until ctx
+// is done.  Then the items already in the queue are drained (handled with a context that is not cancelled, new
+// pushes are still taken until the queue is seen empty), the in-flight items finish and the context error is
+// returned.  A panic in the handler is reported as a *PanicError error.
+func ConsumeQueue[T comparable](ctx context.Context, q *TypedQueue[T], workers int, handler func(ctx context.Context, item T) error, opts ...ConsumeOption) error {
+	var cfg consumeConfig
+	for _, o := range opts {
+		o(&cfg)
+	}
+	var limiter *RateLimiter
+	if cfg.rate > 0 {
+		limiter = NewRateLimiter(cfg.rate, 1)
+	}
+	drainCtx := context.WithoutCancel(ctx)
+	handle := func(hctx context.Context, item T) {
+		err := safeRun(func() error { return handler(hctx, item) })
+		if err != nil && cfg.onError != nil {
+			cfg.onError(fmt.Errorf("item %v: %w", item, err))
+		}
+	}
+	var g Group
+	for w := 0; w < max(workers, 1); w++ {
+		g.Go(func() error {
+			for ctx.Err() == nil {
+				item, err := q.PopWait(ctx)
+				if err != nil {
+					break
+				}
+				// the token is taken once there is an item, so idle workers do not hold tokens for a burst.  an
+				// item popped is handled even if ctx is done meanwhile
+				if limiter != nil {
+					_ = limiter.Wait(drainCtx)
+				}
+				handle(ctx, item)
+			}
+			if cfg.noDrain {
+				return nil
+			}
+			for {
+				item, err := q.Pop()
+				if err != nil {
+					return nil
+				}
+				if limiter != nil {
+					_ = limiter.Wait(drainCtx)
+				}
+				handle(drainCtx, item)
+			}
+		})
+	}
+	g.Wait()
+	return ctx.Err()
+}
@@ -0,0 +1,41 @@
+package razutils
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestConsumeQueueRateIdle - workers waiting on an idle queue do not save up tokens, a burst of pushed items is
+// still handled at the rate
+func TestConsumeQueueRateIdle(t *testing.T) {
+	q := MakeTypedQueue[int](0)
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	var times []time.Time
+	done := make(chan error)
+	go func() {
+		done <- ConsumeQueue(ctx, &q, 4, func(context.Context, int) error {
+			mu.Lock()
+			times = append(times, time.Now())
+			mu.Unlock()
+			return nil
+		}, ConsumeRate(10))
+	}()
+	time.Sleep(500 * time.Millisecond)
+	for i := range 4 {
+		q.Push(i)
+	}
+	time.Sleep(600 * time.Millisecond)
+	cancel()
+	<-done
+	mu.Lock()
+	defer mu.Unlock()
+	if len(times) != 4 {
+		t.Fatalf("%d items handled", len(times))
+	}
+	if span := times[3].Sub(times[0]); span < 250*time.Millisecond {
+		t.Fatalf("4 items handled within %v at 10 per second", span)
+	}
+}
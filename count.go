@@ -0,0 +1,89 @@
+package razutils
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"unicode"
+)
+
+// TextStats - the counts returned by FileStats
+type TextStats struct {
+	Lines int64
+	Words int64
+	Bytes int64
+}
+
+// CountLines - count the lines in a file. The file is streamed in chunks so it works for any file size.
+// A last line without a line ending is counted as well (unlike wc -l).
+func CountLines(path string) (int64, error) {
+	f, err := os.Open(LongPath(path))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	buf := make([]byte, chunkSize)
+	var lines int64
+	var last byte = '\n'
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			lines += int64(bytes.Count(buf[:n], []byte{'\n'}))
+			last = buf[n-1]
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return lines, err
+		}
+	}
+	if last != '\n' {
+		lines++
+	}
+	return lines, nil
+}
+
+// CountWords - count the white space separated words in a (utf-8) file, streaming it.
+func CountWords(path string) (int64, error) {
+	st, err := FileStats(path)
+	return st.Words, err
+}
+
+// FileStats - count lines, words and bytes of a file in a single streaming pass (like wc).
+func FileStats(path string) (TextStats, error) {
+	var st TextStats
+	f, err := os.Open(LongPath(path))
+	if err != nil {
+		return st, err
+	}
+	defer f.Close()
+	r := bufio.NewReaderSize(f, chunkSize)
+	inWord := false
+	lastNL := true
+	for {
+		c, size, err := r.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return st, err
+		}
+		st.Bytes += int64(size)
+		lastNL = c == '\n'
+		if lastNL {
+			st.Lines++
+		}
+		if unicode.IsSpace(c) {
+			inWord = false
+		} else if !inWord {
+			inWord = true
+			st.Words++
+		}
+	}
+	if !lastNL {
+		st.Lines++
+	}
+	return st, nil
+}
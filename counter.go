@@ -0,0 +1,96 @@
+package razutils
+
+import (
+	"sort"
+	"sync"
+)
+
+/*
+Counter - a thread safe frequency counter, e.g. for extension histograms or duplicate statistics.
+*/
+
+// CountEntry - an item and its count, as returned by TopN
+type CountEntry[T comparable] struct {
+	Item  T
+	Count int
+}
+
+type Counter[T comparable] struct {
+	counts map[T]int
+	mu     sync.Mutex
+}
+
+// NewCounter - create an empty counter
+func NewCounter[T comparable]() *Counter[T] {
+	return &Counter[T]{counts: make(map[T]int)}
+}
+
+// Inc - add 1 to the count of item
+func (c *Counter[T]) Inc(item T) {
+	c.Add(item, 1)
+}
+
+// Add - add n to the count of item
+func (c *Counter[T]) Add(item T, n int) {
+	c.mu.Lock()
+	c.counts[item] += n
+	c.mu.Unlock()
+}
+
+// Get - return the count of item (0 if never counted)
+func (c *Counter[T]) Get(item T) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[item]
+}
+
+// Len - return the number of distinct items
+func (c *Counter[T]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.counts)
+}
+
+// Total - return the sum of all counts
+func (c *Counter[T]) Total() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	total := 0
+	for _, n := range c.counts {
+		total += n
+	}
+	return total
+}
+
+// TopN - return the n items with the highest counts, highest first. n <= 0 returns all the items.
+func (c *Counter[T]) TopN(n int) []CountEntry[T] {
+	c.mu.Lock()
+	entries := make([]CountEntry[T], 0, len(c.counts))
+	for item, cnt := range c.counts {
+		entries = append(entries, CountEntry[T]{Item: item, Count: cnt})
+	}
+	c.mu.Unlock()
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Count > entries[j].Count })
+	if n > 0 && n < len(entries) {
+		entries = entries[:n]
+	}
+	return entries
+}
+
+// Merge - add all the counts of other into c
+func (c *Counter[T]) Merge(other *Counter[T]) {
+	if other == c {
+		return
+	}
+	other.mu.Lock()
+	counts := make(map[T]int, len(other.counts))
+	for item, n := range other.counts {
+		counts[item] = n
+	}
+	other.mu.Unlock()
+	c.mu.Lock()
+	for item, n := range counts {
+		c.counts[item] += n
+	}
+	c.mu.Unlock()
+}
@@ -0,0 +1,177 @@
+package razutils
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+CronSchedule - a parsed standard 5 field cron expression: minute hour day-of-month month day-of-week.
+Each field accepts *, a number, a range a-b, a step (a-b/n, or star/n for the whole range) and comma separated
+lists of these.  Months and week days may also be given by their 3 letter English names (jan, mon), Sunday is 0 or 7.
+The aliases @yearly (@annually), @monthly, @weekly, @daily (@midnight) and @hourly are supported as well.
+As in cron, if both day-of-month and day-of-week are restricted a day matching either one is a match.
+*/
+
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64 // bit i is set if value i is allowed
+	domStar, dowStar              bool
+}
+
+type cronField struct {
+	min, max int
+	names    []string // names for the values from min
+}
+
+var cronFields = [5]cronField{
+	{0, 59, nil},
+	{0, 23, nil},
+	{1, 31, nil},
+	{1, 12, []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{0, 7, []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+var cronAliases = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron - parse a cron expression such as "*/15 2-5 * * mon-fri"
+func ParseCron(expr string) (*CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if a, ok := cronAliases[strings.ToLower(expr)]; ok {
+		expr = a
+	}
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("cron %q: expected 5 fields, got %d", expr, len(parts))
+	}
+	var masks [5]uint64
+	for i, p := range parts {
+		m, err := parseCronField(p, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("cron %q: %w", expr, err)
+		}
+		masks[i] = m
+	}
+	// sunday as 7
+	if masks[4]&(1<<7) != 0 {
+		masks[4] = masks[4]&^(1<<7) | 1
+	}
+	return &CronSchedule{
+		minute: masks[0], hour: masks[1], dom: masks[2], month: masks[3], dow: masks[4],
+		domStar: strings.HasPrefix(parts[2], "*"), dowStar: strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+// parseCronField - parse one field into a bit mask
+func parseCronField(s string, f cronField) (uint64, error) {
+	var mask uint64
+	for _, item := range strings.Split(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("bad step in %q", item)
+			}
+			step = n
+		}
+		lo, hi := f.min, f.max
+		switch {
+		case rng == "*":
+		default:
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = cronValue(a, f); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = cronValue(b, f); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = f.max // "5/10" is 5-max/10
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("bad range %q", rng)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			mask |= 1 << v
+		}
+	}
+	return mask, nil
+}
+
+// cronValue - parse a single value, number or name
+func cronValue(s string, f cronField) (int, error) {
+	for i, n := range f.names {
+		if strings.EqualFold(s, n) {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("bad value %q (allowed %d-%d)", s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// dayMatches - check the day against the day-of-month and day-of-week fields
+func (c *CronSchedule) dayMatches(t time.Time) bool {
+	domOk := c.dom&(1<<t.Day()) != 0
+	dowOk := c.dow&(1<<t.Weekday()) != 0
+	if c.domStar || c.dowStar {
+		return domOk && dowOk
+	}
+	return domOk || dowOk
+}
+
+// Next - return the first matching time strictly after t (in t's location), or the zero time if the schedule never
+// matches (e.g. "0 0 30 2 *")
+func (c *CronSchedule) Next(t time.Time) time.Time {
+	// stepping is done on the wall clock: truncating the absolute time is wrong in zones with a half hour offset
+	next := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, t.Location())
+	if !next.After(t) {
+		// an ambiguous wall time (the clock going back) resolved to its earlier instant
+		next = t.Truncate(time.Minute).Add(time.Minute)
+	}
+	t = next
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<t.Month()) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hour&(1<<t.Hour()) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minute&(1<<t.Minute()) == 0 {
+			// jump to the next allowed minute in this hour, if any
+			rest := c.minute >> (t.Minute() + 1) << (t.Minute() + 1)
+			if rest == 0 {
+				t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			} else {
+				t = t.Add(time.Duration(bits.TrailingZeros64(rest)-t.Minute()) * time.Minute)
+			}
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
@@ -0,0 +1,27 @@
+package razutils
+
+import (
+	"testing"
+	"time"
+)
+
+// TestCronNextHalfHourZone - hourly schedules must reach minute 0 of the wall clock in zones offset by half an hour
+func TestCronNextHalfHourZone(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skip("no time zone data:", err)
+	}
+	c, err := ParseCron("0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := c.Next(time.Date(2026, 1, 1, 10, 45, 0, 0, loc))
+	if want := time.Date(2026, 1, 1, 11, 0, 0, 0, loc); !got.Equal(want) {
+		t.Fatalf("Next = %v, want %v", got, want)
+	}
+	c, _ = ParseCron("30 9 * * *")
+	got = c.Next(time.Date(2026, 1, 1, 10, 45, 0, 0, loc))
+	if want := time.Date(2026, 1, 2, 9, 30, 0, 0, loc); !got.Equal(want) {
+		t.Fatalf("Next = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,63 @@
+package razutils
+
+import (
+	"sync"
+	"time"
+)
+
+/*
+Debounce and Throttle - wrap a function so a storm of triggers (e.g. file events during a large copy) runs it only a
+few times.  The returned functions are safe to call from multiple goroutines, fn itself runs in its own goroutine and
+never concurrently with itself.
+*/
+
+// Debounce - return a trigger that calls fn once d has passed with no further triggers (the timer restarts on each
+// trigger)
+func Debounce(d time.Duration, fn func()) func() {
+	var mu sync.Mutex
+	var t *time.Timer
+	var running sync.Mutex
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if t != nil {
+			t.Stop()
+		}
+		t = time.AfterFunc(d, func() {
+			running.Lock()
+			defer running.Unlock()
+			fn()
+		})
+	}
+}
+
+// Throttle - return a trigger that calls fn at once and then at most once every d.  triggers during the wait are
+// coalesced into a single trailing call at the end of it, so the last trigger is never lost.
+func Throttle(d time.Duration, fn func()) func() {
+	var mu sync.Mutex
+	var last time.Time
+	var timer *time.Timer // a trailing call is scheduled
+	var running sync.Mutex
+	var run func()
+	run = func() {
+		mu.Lock()
+		last = time.Now()
+		timer = nil
+		mu.Unlock()
+		running.Lock()
+		defer running.Unlock()
+		fn()
+	}
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if timer != nil {
+			return
+		}
+		wait := d - time.Since(last)
+		if wait <= 0 {
+			wait = 0
+		}
+		timer = time.AfterFunc(wait, run)
+	}
+}
@@ -0,0 +1,144 @@
+package razutils
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotReady - returned by DelayQueue.Pop when there are items but none is ready yet
+var ErrNotReady = errors.New("no item ready")
+
+/*
+DelayQueue - a thread safe queue where each item becomes visible only at its ready time ("retry in 5 minutes").
+Pop returns the ready item with the earliest ready time, PopWait blocks until one becomes ready, so workers do not
+need to sleep on items themselves.
+*/
+
+type dqItem[T any] struct {
+	value   T
+	readyAt time.Time
+	seq     uint64
+}
+
+type dqHeap[T any] []dqItem[T]
+
+func (h dqHeap[T]) Len() int { return len(h) }
+func (h dqHeap[T]) Less(i, j int) bool {
+	if h[i].readyAt.Equal(h[j].readyAt) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].readyAt.Before(h[j].readyAt)
+}
+func (h dqHeap[T]) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *dqHeap[T]) Push(x any)   { *h = append(*h, x.(dqItem[T])) }
+func (h *dqHeap[T]) Pop() any {
+	old := *h
+	n := len(old)
+	it := old[n-1]
+	old[n-1] = dqItem[T]{}
+	*h = old[:n-1]
+	return it
+}
+
+type DelayQueue[T any] struct {
+	h       dqHeap[T]
+	seq     uint64
+	changed chan struct{} // closed and replaced when an item is pushed, to wake the waiters
+	mu      sync.Mutex
+}
+
+// NewDelayQueue - create an empty delay queue
+func NewDelayQueue[T any]() *DelayQueue[T] {
+	return &DelayQueue[T]{changed: make(chan struct{})}
+}
+
+// Push - add an item that becomes ready at readyAt
+func (q *DelayQueue[T]) Push(item T, readyAt time.Time) {
+	q.mu.Lock()
+	q.seq++
+	heap.Push(&q.h, dqItem[T]{value: item, readyAt: readyAt, seq: q.seq})
+	close(q.changed)
+	q.changed = make(chan struct{})
+	q.mu.Unlock()
+}
+
+// PushAfter - add an item that becomes ready after d
+func (q *DelayQueue[T]) PushAfter(item T, d time.Duration) {
+	q.Push(item, time.Now().Add(d))
+}
+
+// Pop - remove and return the earliest ready item.  ErrQueueEmpty is returned for an empty queue and ErrNotReady
+// when no item is ready yet
+func (q *DelayQueue[T]) Pop() (T, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	item, _, err := q.popLocked()
+	return item, err
+}
+
+// popLocked - pop the top item if ready, otherwise return how long until it is (must be called under lock)
+func (q *DelayQueue[T]) popLocked() (T, time.Duration, error) {
+	var zero T
+	if len(q.h) == 0 {
+		return zero, 0, ErrQueueEmpty
+	}
+	if wait := time.Until(q.h[0].readyAt); wait > 0 {
+		return zero, wait, ErrNotReady
+	}
+	return heap.Pop(&q.h).(dqItem[T]).value, 0, nil
+}
+
+// PopWait - remove and return the earliest ready item, blocking until an item is ready or the context is done
+func (q *DelayQueue[T]) PopWait(ctx context.Context) (T, error) {
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for {
+		q.mu.Lock()
+		item, wait, err := q.popLocked()
+		changed := q.changed
+		q.mu.Unlock()
+		if err == nil {
+			return item, nil
+		}
+		var ready <-chan time.Time
+		if err == ErrNotReady {
+			if timer == nil {
+				timer = time.NewTimer(wait)
+			} else {
+				timer.Reset(wait)
+			}
+			ready = timer.C
+		}
+		select {
+		case <-ready:
+		case <-changed:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// Len - return the number of items, ready or not
+func (q *DelayQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.h)
+}
+
+// NextReady - return when the earliest item becomes ready. false if the queue is empty
+func (q *DelayQueue[T]) NextReady() (time.Time, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.h) == 0 {
+		return time.Time{}, false
+	}
+	return q.h[0].readyAt, true
+}
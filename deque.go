@@ -0,0 +1,122 @@
+package razutils
+
+import (
+	"sync"
+)
+
+/*
+Deque - a thread safe double-ended queue, items can be added and removed at both ends (e.g. for work stealing:
+the owner works on one end while others steal from the other).  Like the Queue all access is done under a Mutex.
+It is implemented as a growing ring buffer so operations at both ends are O(1).
+*/
+
+type Deque[T any] struct {
+	buf  []T
+	head int // index of the front item
+	n    int // number of items
+	mu   sync.Mutex
+}
+
+// MakeDeque - create a new Deque with a starting capacity
+func MakeDeque[T any](initSize int) Deque[T] {
+	if initSize <= 0 {
+		return Deque[T]{}
+	}
+	return Deque[T]{buf: make([]T, initSize)}
+}
+
+// grow - make room for at least one more item (must be called under lock)
+func (d *Deque[T]) grow() {
+	if d.n < len(d.buf) {
+		return
+	}
+	nb := make([]T, max(2*len(d.buf), 8))
+	for i := 0; i < d.n; i++ {
+		nb[i] = d.buf[(d.head+i)%len(d.buf)]
+	}
+	d.buf, d.head = nb, 0
+}
+
+// PushFront - add an item at the front
+func (d *Deque[T]) PushFront(item T) {
+	d.mu.Lock()
+	d.grow()
+	d.head = (d.head - 1 + len(d.buf)) % len(d.buf)
+	d.buf[d.head] = item
+	d.n++
+	d.mu.Unlock()
+}
+
+// PushBack - add an item at the back
+func (d *Deque[T]) PushBack(item T) {
+	d.mu.Lock()
+	d.grow()
+	d.buf[(d.head+d.n)%len(d.buf)] = item
+	d.n++
+	d.mu.Unlock()
+}
+
+// PopFront - remove and return the front item. Error is returned if the deque is empty
+func (d *Deque[T]) PopFront() (T, error) {
+	var zero T
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.n == 0 {
+		return zero, ErrQueueEmpty
+	}
+	item := d.buf[d.head]
+	d.buf[d.head] = zero // do not keep a reference to the removed item
+	d.head = (d.head + 1) % len(d.buf)
+	d.n--
+	return item, nil
+}
+
+// PopBack - remove and return the back item. Error is returned if the deque is empty
+func (d *Deque[T]) PopBack() (T, error) {
+	var zero T
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.n == 0 {
+		return zero, ErrQueueEmpty
+	}
+	i := (d.head + d.n - 1) % len(d.buf)
+	item := d.buf[i]
+	d.buf[i] = zero
+	d.n--
+	return item, nil
+}
+
+// Front - return the front item without removing it. Error is returned if the deque is empty
+func (d *Deque[T]) Front() (T, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.n == 0 {
+		var zero T
+		return zero, ErrQueueEmpty
+	}
+	return d.buf[d.head], nil
+}
+
+// Back - return the back item without removing it. Error is returned if the deque is empty
+func (d *Deque[T]) Back() (T, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.n == 0 {
+		var zero T
+		return zero, ErrQueueEmpty
+	}
+	return d.buf[(d.head+d.n-1)%len(d.buf)], nil
+}
+
+// Len - return the number of items
+func (d *Deque[T]) Len() int {
+	d.mu.Lock()
+	res := d.n
+	d.mu.Unlock()
+	return res
+}
+
+// IsEmpty - check if the deque is empty
+func (d *Deque[T]) IsEmpty() bool {
+	return d.Len() == 0
+}
@@ -0,0 +1,80 @@
+package razutils
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+/*
+Every - run a function periodically on a fixed schedule: the run times are start + n*interval, so unlike a sleep
+after each run (or a misused Ticker) the schedule does not drift.  When a run takes longer than the interval the
+missed ticks are skipped and the schedule continues from the next tick, with EveryCatchUp a single extra run is done
+right away for all the missed ticks instead.  EveryJitter delays each run by a random amount, so many daemons do not
+hit a server at the same moment.
+
+	err := Every(ctx, 10*time.Minute, scan, EveryJitter(30*time.Second))
+*/
+
+// EveryOption - an option for Every
+type EveryOption func(*everyConfig)
+
+type everyConfig struct {
+	jitter    time.Duration
+	catchUp   bool
+	immediate bool
+}
+
+// EveryJitter - delay each run by a random duration of up to d (not affecting the schedule)
+func EveryJitter(d time.Duration) EveryOption {
+	return func(c *everyConfig) { c.jitter = d }
+}
+
+// EveryCatchUp - after a run that overran one or more ticks, run once more at once instead of waiting for the next tick
+func EveryCatchUp() EveryOption {
+	return func(c *everyConfig) { c.catchUp = true }
+}
+
+// EveryImmediately - do the first run at once instead of after the first interval
+func EveryImmediately() EveryOption {
+	return func(c *everyConfig) { c.immediate = true }
+}
+
+// Every - call fn every interval until ctx is done (returning the context error) or fn returns an error (returned
+// as is)
+func Every(ctx context.Context, interval time.Duration, fn func(ctx context.Context) error, opts ...EveryOption) error {
+	if interval <= 0 {
+		return errors.New("every: interval must be positive")
+	}
+	var cfg everyConfig
+	for _, o := range opts {
+		o(&cfg)
+	}
+	start := time.Now()
+	n := int64(1)
+	if cfg.immediate {
+		n = 0
+	}
+	for {
+		next := start.Add(time.Duration(n) * interval)
+		wait := time.Until(next)
+		if cfg.jitter > 0 {
+			wait += RandDuration(0, cfg.jitter)
+		}
+		if err := SleepCtx(ctx, wait); err != nil {
+			return err
+		}
+		if err := fn(ctx); err != nil {
+			return err
+		}
+		// the next tick after now, skipping the ones missed while fn ran
+		missed := int64(time.Since(start)/interval) - n
+		n++
+		if missed > 0 {
+			n += missed
+			if cfg.catchUp {
+				n-- // the tick that just passed, which is due at once
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package razutils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+/*
+Exit codes used by command line tools built on this package, so wrapping shell scripts can tell apart the different
+failure reasons.  An error can carry its own exit code by implementing ExitCoder, or by being wrapped with
+NewExitError.  Errors that do not carry a code map to ExitFailure.
+
+	0  ExitOK            - all went well
+	1  ExitFailure       - generic failure
+	2  ExitUsage         - bad arguments / command line usage
+	3  ExitNothingToDo   - the run completed but there was nothing to process
+	4  ExitVerifyFailed  - a verification (compare, checksum, archive test) failed
+	75 ExitTransient     - temporary failure (network etc.), retrying later may work (same as sysexits EX_TEMPFAIL)
+*/
+
+const (
+	ExitOK           = 0
+	ExitFailure      = 1
+	ExitUsage        = 2
+	ExitNothingToDo  = 3
+	ExitVerifyFailed = 4
+	ExitTransient    = 75
+)
+
+// ExitCoder - an error that knows which process exit code it should cause
+type ExitCoder interface {
+	error
+	ExitCode() int
+}
+
+// exitError - a simple error carrying an exit code, optionally wrapping another error
+type exitError struct {
+	code int
+	msg  string
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return e.msg
+	}
+	if e.msg == "" {
+		return e.err.Error()
+	}
+	return e.msg + ": " + e.err.Error()
+}
+
+func (e *exitError) Unwrap() error { return e.err }
+
+func (e *exitError) ExitCode() int { return e.code }
+
+// The common errors, can be returned as is or wrapped with fmt.Errorf("...: %w", ErrXXX)
+var (
+	ErrUsage        = &exitError{code: ExitUsage, msg: "usage error"}
+	ErrNothingToDo  = &exitError{code: ExitNothingToDo, msg: "nothing to do"}
+	ErrVerifyFailed = &exitError{code: ExitVerifyFailed, msg: "verification failed"}
+	ErrTransient    = &exitError{code: ExitTransient, msg: "transient error"}
+)
+
+// NewExitError - wrap an error with a specific exit code. a nil err creates a new error with a generic message.
+func NewExitError(code int, err error) error {
+	if err == nil {
+		return &exitError{code: code, msg: fmt.Sprintf("exit code %d", code)}
+	}
+	return &exitError{code: code, err: err}
+}
+
+// ExitCodeOf - return the exit code matching the error. nil is ExitOK, errors that do not implement ExitCoder
+// (anywhere in their wrap chain) are ExitFailure.
+func ExitCodeOf(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var ec ExitCoder
+	if errors.As(err, &ec) {
+		return ec.ExitCode()
+	}
+	return ExitFailure
+}
+
+// Exit - terminate the program with the exit code matching err.  the error (if any) is printed to stderr first.
+func Exit(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	os.Exit(ExitCodeOf(err))
+}
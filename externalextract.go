@@ -0,0 +1,150 @@
+package razutils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+/*
+External extraction backends for the formats Go can not decode natively (7z, RAR), running the 7z or unrar programs
+when they are installed.  Callers can check Have7z/HaveUnrar first and degrade gracefully (e.g. skip the file).
+*/
+
+// Extractor - something that can extract an archive file into a directory
+type Extractor interface {
+	Extract(ctx context.Context, src string, destDir string) error
+}
+
+// ErrExtractorNotFound - the external program needed is not installed
+var ErrExtractorNotFound = errors.New("extractor program not found")
+
+// ExternalError - a failed run of an external program, with its output
+type ExternalError struct {
+	Cmd    string
+	Output string
+	Err    error
+}
+
+func (e *ExternalError) Error() string {
+	out := strings.TrimSpace(e.Output)
+	if i := strings.LastIndexByte(out, '\n'); i >= 0 {
+		out = out[i+1:] // the last line is usually the error message
+	}
+	return fmt.Sprintf("%s: %v: %s", e.Cmd, e.Err, out)
+}
+
+func (e *ExternalError) Unwrap() error { return e.Err }
+
+// ExternalExtractor - an Extractor running an external program
+type ExternalExtractor struct {
+	Binary  string                             // the program path
+	Args    func(src, destDir string) []string // the command line arguments
+	Timeout time.Duration                      // 0 for none
+}
+
+// Extract - run the program to extract src into destDir, failing with an *ExternalError if it fails
+func (x *ExternalExtractor) Extract(ctx context.Context, src string, destDir string) error {
+	if x.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, x.Timeout)
+		defer cancel()
+	}
+	if err := os.MkdirAll(LongPath(destDir), 0o755); err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, x.Binary, x.Args(src, destDir)...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	cmd.Stdin = nil // no password prompts
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return &ExternalError{Cmd: filepath.Base(x.Binary), Output: out.String(), Err: err}
+	}
+	return nil
+}
+
+// findBinary - look for the first of the programs in the PATH, then in the extra locations
+func findBinary(names []string, extra []string) (string, bool) {
+	for _, n := range names {
+		if p, err := exec.LookPath(n); err == nil {
+			return p, true
+		}
+	}
+	for _, p := range extra {
+		if exists, _ := FileExists(p); exists {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// Find7z - return the path of the 7-Zip program (7z, 7zz or 7za)
+func Find7z() (string, bool) {
+	var extra []string
+	if runtime.GOOS == "windows" {
+		extra = []string{`C:\Program Files\7-Zip\7z.exe`, `C:\Program Files (x86)\7-Zip\7z.exe`}
+	}
+	return findBinary([]string{"7z", "7zz", "7za"}, extra)
+}
+
+// FindUnrar - return the path of the unrar program
+func FindUnrar() (string, bool) {
+	var extra []string
+	if runtime.GOOS == "windows" {
+		extra = []string{`C:\Program Files\WinRAR\UnRAR.exe`, `C:\Program Files (x86)\WinRAR\UnRAR.exe`}
+	}
+	return findBinary([]string{"unrar"}, extra)
+}
+
+// Have7z - check if 7-Zip is installed
+func Have7z() bool {
+	_, ok := Find7z()
+	return ok
+}
+
+// HaveUnrar - check if unrar is installed
+func HaveUnrar() bool {
+	_, ok := FindUnrar()
+	return ok
+}
+
+// New7zExtractor - an extractor running 7-Zip (handles 7z, rar and most other formats). ErrExtractorNotFound if
+// it is not installed
+func New7zExtractor() (*ExternalExtractor, error) {
+	bin, ok := Find7z()
+	if !ok {
+		return nil, fmt.Errorf("%w: 7z", ErrExtractorNotFound)
+	}
+	return &ExternalExtractor{Binary: bin, Args: sevenZipArgs}, nil
+}
+
+// sevenZipArgs - the 7z command line.  -p- fails instead of asking for a password, and -- ends the switches so a
+// src starting with '-' is not taken as one
+func sevenZipArgs(src, destDir string) []string {
+	return []string{"x", "-y", "-p-", "-o" + destDir, "--", src}
+}
+
+// NewUnrarExtractor - an extractor running unrar. ErrExtractorNotFound if it is not installed
+func NewUnrarExtractor() (*ExternalExtractor, error) {
+	bin, ok := FindUnrar()
+	if !ok {
+		return nil, fmt.Errorf("%w: unrar", ErrExtractorNotFound)
+	}
+	return &ExternalExtractor{Binary: bin, Args: unrarArgs}, nil
+}
+
+// unrarArgs - the unrar command line, -- ends the switches as for sevenZipArgs
+func unrarArgs(src, destDir string) []string {
+	return []string{"x", "-o+", "-y", "-p-", "--", src, destDir + string(filepath.Separator)}
+}
@@ -0,0 +1,17 @@
+package razutils
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestExternalArgsDashName - an archive name starting with '-' comes after "--", so it is not taken as a switch
+func TestExternalArgsDashName(t *testing.T) {
+	for _, args := range []func(src, destDir string) []string{sevenZipArgs, unrarArgs} {
+		a := args("-evil.rar", "out")
+		end := slices.Index(a, "--")
+		if src := slices.Index(a, "-evil.rar"); end == -1 || src < end {
+			t.Fatalf("archive name parsed as a switch: %q", a)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package razutils
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnsupportedArchive - the file is not an archive type that can be extracted
+var ErrUnsupportedArchive = errors.New("unsupported archive type")
+
+// compressionExts - the extensions of single file compressions, removed to name the extracted file
+var compressionExts = []string{".gz", ".zst", ".bz2", ".xz"}
+
+// ExtractArchive - extract src into destDir, detecting its type by content (magic bytes) regardless of its name:
+// zip, tar (plain or compressed by gzip, zstd, bzip2 or xz), rar (see RarExtract) and single compressed files
+// (written to destDir under the src name without the compression extension).  7z is extracted by the external 7z
+// program when installed.  ErrUnsupportedArchive is returned for other files.  Split archives are extracted
+// given the first part ("movie.zip.001") or the name without the part number (see FindParts).
+func ExtractArchive(src string, destDir string, opts ...ArchiveOption) error {
+	fin, err := openArchive(src)
+	if err != nil {
+		return err
+	}
+	h := make([]byte, sniffLen)
+	n, _ := fin.ReadAt(h, 0)
+	parts := fin.parts
+	fin.Close()
+	t := detectType(h[:n])
+	switch t {
+	case FileTypeZip:
+		return ZipExtract(src, destDir, opts...)
+	case FileTypeTar, FileTypeGzip, FileTypeZstd, FileTypeBzip2, FileTypeXz:
+		return extractCompressed(src, destDir, opts)
+	case FileTypeRar:
+		return RarExtract(src, destDir, opts...)
+	case FileType7z:
+		if cfg := archiveOptions(opts); len(cfg.match) > 0 || cfg.flatten {
+			return fmt.Errorf("%w: selective extraction of %s", ErrUnsupportedArchive, t)
+		}
+		x, err := New7zExtractor()
+		if err != nil {
+			return fmt.Errorf("%w %s: %w", ErrUnsupportedArchive, t, err)
+		}
+		// 7z takes the first part of split archives
+		return x.Extract(context.Background(), parts[0], destDir)
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupportedArchive, src)
+}
+
+// extractCompressed - extract a tar, compressed or not, or a single compressed file
+func extractCompressed(src string, destDir string, opts []ArchiveOption) error {
+	cfg := archiveOptions(opts)
+	fin, err := openArchive(src)
+	if err != nil {
+		return err
+	}
+	defer fin.Close()
+	cfg.progress.setTotal(fin.Size())
+	r, err := NewAutoDecompressor(cfg.progress.reader(fin))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	br := bufio.NewReaderSize(r, sniffLen)
+	if h, _ := br.Peek(sniffLen); detectType(h) == FileTypeTar {
+		return tarExtractStream(br, destDir, &cfg)
+	}
+	name := decompressedName(fin.name)
+	if !cfg.wants(name, true) {
+		return nil
+	}
+	cfg.progress.entry(name)
+	return writeEntryFile(filepath.Join(destDir, name), br, 0o644, fin.mtime)
+}
+
+// decompressedName - the name of the file decompressed from src, its base name without the compression extension
+// (or with ".out" added if it has none)
+func decompressedName(src string) string {
+	name := filepath.Base(src)
+	for _, ext := range compressionExts {
+		if strings.EqualFold(filepath.Ext(name), ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	return name + ".out"
+}
+
+// ExtractMatching - extract from src only the files matching one of the glob patterns (e.g. "*.srt", see
+// WithMatch), add WithFlatten to put them all directly in destDir.  Formats handled by external programs (7z, rar)
+// are not supported.
+func ExtractMatching(src string, destDir string, patterns []string, opts ...ArchiveOption) error {
+	return ExtractArchive(src, destDir, append(opts, WithMatch(patterns...))...)
+}
@@ -0,0 +1,160 @@
+package razutils
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// FileType - a file content type, as detected by the magic bytes at the file start
+type FileType string
+
+const (
+	FileTypeUnknown FileType = ""
+	// video
+	FileTypeMKV    FileType = "mkv"
+	FileTypeWebM   FileType = "webm"
+	FileTypeMP4    FileType = "mp4"
+	FileTypeMOV    FileType = "mov"
+	FileTypeAVI    FileType = "avi"
+	FileTypeMPEG   FileType = "mpeg"
+	FileTypeMPEGTS FileType = "mpegts"
+	FileTypeFLV    FileType = "flv"
+	FileTypeASF    FileType = "asf" // wmv/wma
+	FileTypeOGG    FileType = "ogg"
+	// audio
+	FileTypeWAV FileType = "wav"
+	FileTypeMP3 FileType = "mp3"
+	// archives
+	FileTypeGzip  FileType = "gzip"
+	FileTypeZip   FileType = "zip"
+	FileTypeBzip2 FileType = "bzip2"
+	FileTypeXz    FileType = "xz"
+	FileTypeZstd  FileType = "zstd"
+	FileType7z    FileType = "7z"
+	FileTypeRar   FileType = "rar"
+	FileTypeTar   FileType = "tar"
+	// others
+	FileTypePDF  FileType = "pdf"
+	FileTypePNG  FileType = "png"
+	FileTypeJPEG FileType = "jpeg"
+	FileTypeGIF  FileType = "gif"
+)
+
+// sniffLen - number of bytes needed by detectType
+const sniffLen = 512
+
+// IsVideo - check if the type is one of the video containers
+func (t FileType) IsVideo() bool {
+	switch t {
+	case FileTypeMKV, FileTypeWebM, FileTypeMP4, FileTypeMOV, FileTypeAVI, FileTypeMPEG, FileTypeMPEGTS,
+		FileTypeFLV, FileTypeASF, FileTypeOGG:
+		return true
+	}
+	return false
+}
+
+// IsArchive - check if the type is a compressed file or an archive
+func (t FileType) IsArchive() bool {
+	switch t {
+	case FileTypeGzip, FileTypeZip, FileTypeBzip2, FileTypeXz, FileTypeZstd, FileType7z, FileTypeRar, FileTypeTar:
+		return true
+	}
+	return false
+}
+
+// detectType - detect the type from the first bytes of the content (up to sniffLen bytes are used)
+func detectType(h []byte) FileType {
+	has := func(off int, magic string) bool {
+		return len(h) >= off+len(magic) && string(h[off:off+len(magic)]) == magic
+	}
+	switch {
+	case has(0, "\x1A\x45\xDF\xA3"):
+		// EBML: matroska and webm differ by the DocType string inside the header
+		end := min(len(h), 64)
+		if bytes.Contains(h[:end], []byte("webm")) {
+			return FileTypeWebM
+		}
+		return FileTypeMKV
+	case has(4, "ftyp"):
+		if has(8, "qt  ") {
+			return FileTypeMOV
+		}
+		return FileTypeMP4
+	case has(4, "moov"), has(4, "mdat"), has(4, "wide"), has(4, "free"):
+		return FileTypeMOV // old quicktime files without ftyp
+	case has(0, "RIFF") && has(8, "AVI "):
+		return FileTypeAVI
+	case has(0, "RIFF") && has(8, "WAVE"):
+		return FileTypeWAV
+	case has(0, "\x00\x00\x01\xBA"), has(0, "\x00\x00\x01\xB3"):
+		return FileTypeMPEG
+	case len(h) > 376 && h[0] == 0x47 && h[188] == 0x47 && h[376] == 0x47:
+		return FileTypeMPEGTS
+	case has(0, "FLV\x01"):
+		return FileTypeFLV
+	case has(0, "\x30\x26\xB2\x75\x8E\x66\xCF\x11"):
+		return FileTypeASF
+	case has(0, "OggS"):
+		return FileTypeOGG
+	case has(0, "ID3"), len(h) > 1 && h[0] == 0xFF && h[1]&0xE0 == 0xE0 && h[1]&0x06 != 0:
+		return FileTypeMP3
+	case has(0, "\x1F\x8B"):
+		return FileTypeGzip
+	case has(0, "PK\x03\x04"), has(0, "PK\x05\x06"), has(0, "PK\x07\x08"):
+		return FileTypeZip
+	case has(0, "BZh"):
+		return FileTypeBzip2
+	case has(0, "\xFD7zXZ\x00"):
+		return FileTypeXz
+	case has(0, "\x28\xB5\x2F\xFD"):
+		return FileTypeZstd
+	case has(0, "7z\xBC\xAF\x27\x1C"):
+		return FileType7z
+	case has(0, "Rar!\x1A\x07"):
+		return FileTypeRar
+	case has(257, "ustar"):
+		return FileTypeTar
+	case has(0, "%PDF-"):
+		return FileTypePDF
+	case has(0, "\x89PNG\r\n\x1A\n"):
+		return FileTypePNG
+	case has(0, "\xFF\xD8\xFF"):
+		return FileTypeJPEG
+	case has(0, "GIF87a"), has(0, "GIF89a"):
+		return FileTypeGIF
+	}
+	return FileTypeUnknown
+}
+
+// readHeader - read up to n bytes from the start of the file
+func readHeader(path string, n int) ([]byte, error) {
+	f, err := os.Open(LongPath(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := make([]byte, n)
+	cnt, err := io.ReadFull(f, h)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	return h[:cnt], nil
+}
+
+// DetectFileType - detect the type of file by its content (magic bytes) regardless of its name.
+// FileTypeUnknown is returned for types not recognized.
+func DetectFileType(path string) (FileType, error) {
+	h, err := readHeader(path, sniffLen)
+	if err != nil {
+		return FileTypeUnknown, err
+	}
+	return detectType(h), nil
+}
+
+// IsVideoContent check if a given file is a video file by its content. unlike IsVideoFile the file must exist
+// and be readable, however renamed or extension-less files are identified correctly.
+func IsVideoContent(path string) bool {
+	t, err := DetectFileType(path)
+	return err == nil && t.IsVideo()
+}
@@ -0,0 +1,74 @@
+package razutils
+
+import (
+	"context"
+)
+
+/*
+Future - the result of a function running in the background, e.g. hashing a file while the walk goes on:
+
+	h := Go(func() (string, error) { return FileSHA256(path) })
+	...
+	sum, err := h.Await(ctx)
+
+A panic in the function is returned as a *PanicError.
+*/
+
+type Future[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+}
+
+// Go - run fn in a new goroutine and return its future result
+func Go[T any](fn func() (T, error)) *Future[T] {
+	f := &Future[T]{done: make(chan struct{})}
+	go func() {
+		defer close(f.done)
+		defer recoverError(&f.err)
+		f.val, f.err = fn()
+	}()
+	return f
+}
+
+// Resolved - return a future that is already done with v and err
+func Resolved[T any](v T, err error) *Future[T] {
+	f := &Future[T]{done: make(chan struct{}), val: v, err: err}
+	close(f.done)
+	return f
+}
+
+// Done - a channel closed once the result is ready
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Await - wait for the result, or until ctx is done (then the context error is returned, the function keeps running)
+func (f *Future[T]) Await(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Get - wait for the result with no way to give up
+func (f *Future[T]) Get() (T, error) {
+	<-f.done
+	return f.val, f.err
+}
+
+// Then - return a future of fn applied to the result of f.  fn runs (in the background) only if f succeeded,
+// otherwise the new future fails with the error of f.
+func Then[T, R any](f *Future[T], fn func(T) (R, error)) *Future[R] {
+	return Go(func() (R, error) {
+		v, err := f.Get()
+		if err != nil {
+			var zero R
+			return zero, err
+		}
+		return fn(v)
+	})
+}
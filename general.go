@@ -3,12 +3,14 @@ package razutils
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"math"
-	"math/rand"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -28,7 +30,7 @@ var container = regexp.MustCompile(`(?i)\.(?:MKV|AVI|MP4|MOV|MPG|MPEG|FLV|F4V|SW
 // FileExists check if a file/directory exist at the given path.  Note that if there is an access issue the function will
 // return false,error but the file might exist.
 func FileExists(path string) (bool, error) {
-	_, err := os.Stat(path)
+	_, err := os.Stat(LongPath(path))
 	if err == nil {
 		return true, nil
 	}
@@ -47,6 +49,26 @@ func FileParts(path string) (dir string, file string, ext string) {
 	return dir, file, ext
 }
 
+// FindFileInsensitive - locate a file in dir by name ignoring case (e.g. "Movie.SRT" for "movie.srt"), this is needed
+// on case-sensitive filesystems.  An exact match is preferred, otherwise the first case-insensitive match is returned.
+// The full path of the found file is returned, or an error wrapping os.ErrNotExist if none matched.
+func FindFileInsensitive(dir string, name string) (string, error) {
+	exact := filepath.Join(dir, name)
+	if _, err := os.Stat(exact); err == nil {
+		return exact, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, e := range entries {
+		if strings.EqualFold(e.Name(), name) {
+			return filepath.Join(dir, e.Name()), nil
+		}
+	}
+	return "", fmt.Errorf("%s in %s: %w", name, dir, os.ErrNotExist)
+}
+
 // IsVideoFile check if a given file path is a valid video file name.
 // note: the check is done by extension and not by file content.
 func IsVideoFile(path string) bool {
@@ -66,18 +88,37 @@ func IsSrtFile(path string) bool {
 // ReplaceExt replace the file extension with a new extension.
 // if an empty new extension is specified the existing extension will be removed
 // if the new extension does not start with a ., it will be added
-func ReplaceExt(path string, newExt string) string {
+func ReplaceExt(path string, newExt string) (string, error) {
 	if len(path) == 0 {
-		log.Fatal("bad call to ReplaceExt - path empty")
+		return "", errors.New("ReplaceExt: path empty")
 	}
 	p1 := path[0 : len(path)-len(filepath.Ext(path))]
 	if len(newExt) == 0 {
-		return p1
+		return p1, nil
 	}
 	if newExt[:1] != "." {
-		newExt = "."
+		newExt = "." + newExt
 	}
-	return p1 + newExt
+	return p1 + newExt, nil
+}
+
+// AddSuffix - add a suffix to the file name, just before the extension. e.g. "movie.srt" with "-fixed" becomes
+// "movie-fixed.srt".  The suffix is used as is.
+func AddSuffix(path string, suffix string) string {
+	ext := filepath.Ext(path)
+	return path[0:len(path)-len(ext)] + suffix + ext
+}
+
+// InsertBeforeExt - insert an additional extension before the existing one, e.g. "movie.srt" with ".en" becomes
+// "movie.en.srt".  if the inserted part does not start with a ., it will be added
+func InsertBeforeExt(path string, part string) string {
+	if len(part) == 0 {
+		return path
+	}
+	if part[:1] != "." {
+		part = "." + part
+	}
+	return AddSuffix(path, part)
 }
 
 // RandFileName - return a random file name with an extension as mentioned in extension and prefix as in prefix
@@ -85,7 +126,7 @@ func ReplaceExt(path string, newExt string) string {
 // will cause for sure failure in the long run.
 func RandFileName(path string, prefix string, ext string) string {
 	for cnt := 0; cnt <= 50; cnt++ {
-		r := rand.Intn(99999)
+		r := RandIntn(99999)
 		fn := prefix + strconv.Itoa(r) + "." + ext
 		if _, err := os.Stat(filepath.Join(path, fn)); errors.Is(err, os.ErrNotExist) {
 			return fn
@@ -95,6 +136,35 @@ func RandFileName(path string, prefix string, ext string) string {
 	return ""
 }
 
+// NextAvailableName - return path if it does not exist, otherwise the first free "name (1).ext", "name (2).ext" ...
+// (Windows Explorer style).  A leading dot is part of the name, so ".bashrc" gives ".bashrc (1)".  Use this instead
+// of RandFileName for user visible file names.
+func NextAvailableName(path string) (string, error) {
+	exists, err := FileExists(path)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return path, nil
+	}
+	ext := filepath.Ext(path)
+	if ext == filepath.Base(path) {
+		ext = "" // a dotfile, all name
+	}
+	base := path[0 : len(path)-len(ext)]
+	for i := 1; i <= 9999; i++ {
+		fn := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		exists, err = FileExists(fn)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return fn, nil
+		}
+	}
+	return "", fmt.Errorf("no available name for %s", path)
+}
+
 // Abs return an abs(int)
 func Abs(x int) int {
 	if x < 0 {
@@ -103,36 +173,67 @@ func Abs(x int) int {
 	return x
 }
 
+// ErrSameFile - the source and destination of a copy are the same file
+var ErrSameFile = errors.New("source and destination are the same file")
+
 // CopyFile - copy a file from source to destination path.
+// The copy is streamed and will wait while the package pause controller (SetPauseController) is paused.
+// Copying a file onto itself (also through another name or a link) fails with ErrSameFile.
 func CopyFile(src string, dst string) error {
-	// Read all content of src to data
-	data, err := os.ReadFile(src)
+	in, err := os.Open(LongPath(src))
 	if err != nil {
 		return err
 	}
-	// Write data to dst
-	err = os.WriteFile(dst, data, 0644)
+	defer in.Close()
+	// dst is truncated when opened, which would lose the content if it is src
+	if si, err := in.Stat(); err == nil {
+		if di, err := os.Stat(LongPath(dst)); err == nil && os.SameFile(si, di) {
+			return fmt.Errorf("%w: %s", ErrSameFile, dst)
+		}
+	}
+	out, err := os.OpenFile(LongPath(dst), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 	if err != nil {
 		return err
 	}
-	return nil
+	_, err = io.Copy(out, pausable(in))
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// WriteFileAtomic - write data to a file so readers see either the old or the new content, never a partial file:
+// the data is written to a temporary file in the same directory, synced, and renamed over path.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(LongPath(filepath.Dir(path)), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // fails harmlessly once renamed
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), perm)
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), LongPath(path))
 }
 
 // MoveFile - move/rename a file from source to destination path.
 // currently implemented as a copy+delete.  this is not optimal as same volume rename should be quicker
 // however checking this is more complex
 func MoveFile(src string, dst string) error {
-	// Read all content of src to data
-	data, err := os.ReadFile(src)
-	if err != nil {
-		return err
-	}
-	// Write data to dst
-	err = os.WriteFile(dst, data, 0644)
+	err := CopyFile(src, dst)
 	if err != nil {
 		return err
 	}
-	err = os.Remove(src)
+	err = os.Remove(LongPath(src))
 	if err != nil {
 		return err
 	}
@@ -143,6 +244,7 @@ const chunkSize = 64000
 
 // DeepCompare Compare two files to see if content is the same.
 // The files are read by chunks and the first difference cause the function to return false.
+// Large files are memory mapped instead when MmapThreshold is set.
 func DeepCompare(file1, file2 string) bool {
 	f1s, err := os.Stat(file1)
 	if err != nil {
@@ -155,6 +257,11 @@ func DeepCompare(file1, file2 string) bool {
 	if f1s.Size() != f2s.Size() {
 		return false
 	}
+	if useMmap(f1s.Size()) {
+		if same, err := mmapCompare(file1, file2); err == nil {
+			return same
+		}
+	}
 	f1, err := os.Open(file1)
 	if err != nil {
 		log.Fatal(err)
@@ -164,6 +271,7 @@ func DeepCompare(file1, file2 string) bool {
 		log.Fatal(err)
 	}
 	for {
+		_ = pauseWait(context.Background())
 		b1 := make([]byte, chunkSize)
 		_, err1 := f1.Read(b1)
 		b2 := make([]byte, chunkSize)
@@ -183,26 +291,125 @@ func DeepCompare(file1, file2 string) bool {
 	}
 }
 
+// mmapCompare - compare two files by memory mapping them (see MmapThreshold)
+func mmapCompare(file1, file2 string) (bool, error) {
+	d1, unmap1, err := MmapFile(file1)
+	if err != nil {
+		return false, err
+	}
+	defer unmap1()
+	d2, unmap2, err := MmapFile(file2)
+	if err != nil {
+		return false, err
+	}
+	defer unmap2()
+	if len(d1) != len(d2) {
+		return false, nil
+	}
+	// compared by chunks to honor the pause controller as the streaming compare does
+	for off := 0; off < len(d1); off += chunkSize {
+		_ = pauseWait(context.Background())
+		end := min(off+chunkSize, len(d1))
+		if !bytes.Equal(d1[off:end], d2[off:end]) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 // GzipExtract - convert a .gz by expanding it into the original file. Source is the gz file path, dest is what the
-// result filename should be
-func GzipExtract(source string, dest string) error {
-	r, err := os.Open(source)
+// result filename should be.  When dest is an existing directory, or empty for the directory of source, the file
+// is named as the original file stored in the gzip header (gzip -N), or as source without its .gz.  The file gets
+// the modification time stored in the header, when there is one.  concatenated gz files (several gzip members, as
+// rotated logs often are) are expanded completely.
+func GzipExtract(source string, dest string, opts ...ArchiveOption) error {
+	cfg := archiveOptions(opts)
+	r, err := openArchive(source)
 	if err != nil {
 		return err
 	}
 	defer r.Close()
-	reader, err := gzip.NewReader(r)
+	cfg.progress.setTotal(r.Size())
+	gz, err := newGzipReader(cfg.progress.reader(r))
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	if dest == "" {
+		dest = filepath.Dir(r.name)
+	}
+	if info, err := os.Stat(LongPath(dest)); err == nil && info.IsDir() {
+		dest = filepath.Join(dest, gzipOriginalName(gz.Name, r.name))
+	}
+	cfg.progress.entry(filepath.Base(dest))
+	return writeEntryFile(dest, gz, 0o644, gz.ModTime)
+}
+
+// gzipOriginalName - the file name from the gzip header, only its base so it can not point elsewhere, or the name of
+// the gz file without its extension when there is no usable name
+func gzipOriginalName(name string, source string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	if len(name) >= 2 && name[1] == ':' {
+		name = name[2:] // a drive with no directory
+	}
+	switch name {
+	case "", ".", "..", "/":
+		return decompressedName(source)
+	}
+	return name
+}
+
+// newGzipReader - a gzip reader going over all the members of the stream, not only the first one
+func newGzipReader(r io.Reader) (*gzip.Reader, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	// this is the default, set explicitly as the whole content of concatenated files must be read
+	gz.Multistream(true)
+	return gz, nil
+}
+
+// GzipCompress - compress the source file into a .gz file at dest (the source is not removed).  With WithWorkers(n)
+// n > 1 the file is compressed by n goroutines (see GzipParallel), WithCompressionLevel sets the level.
+func GzipCompress(source string, dest string, opts ...ArchiveOption) error {
+	cfg := archiveOptions(opts)
+	r, err := os.Open(LongPath(source))
 	if err != nil {
 		return err
 	}
-	defer reader.Close()
-	fout, err := os.Create(dest)
+	defer r.Close()
+	fout, err := os.Create(LongPath(dest))
 	if err != nil {
 		return err
 	}
 	defer fout.Close()
-	_, err = io.Copy(fout, reader)
-	return err
+	hdr := gzip.Header{Name: filepath.Base(source)}
+	if info, err := r.Stat(); err == nil {
+		hdr.ModTime = info.ModTime()
+	}
+	if cfg.workers > 1 {
+		err = GzipParallel(fout, pausable(r), cfg.workers, cfg.level, hdr)
+	} else {
+		err = gzipStream(fout, pausable(r), cfg.level, hdr)
+	}
+	if err != nil {
+		return err
+	}
+	return fout.Close()
+}
+
+// gzipStream - gzip src into dst as a single member with the given header
+func gzipStream(dst io.Writer, src io.Reader, level int, hdr gzip.Header) error {
+	writer, err := gzip.NewWriterLevel(dst, level)
+	if err != nil {
+		return err
+	}
+	writer.Header = hdr
+	if _, err = io.Copy(writer, src); err != nil {
+		return err
+	}
+	return writer.Close()
 }
 
 // DaysSince - computer round number of days between now and specified time in the past (or future)
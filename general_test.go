@@ -0,0 +1,84 @@
+package razutils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestCopyFileSame - copying a file onto itself must fail and keep the content
+func TestCopyFileSame(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "x.txt")
+	os.WriteFile(p, []byte("content"), 0o644)
+	if err := CopyFile(p, p); !errors.Is(err, ErrSameFile) {
+		t.Fatalf("expected ErrSameFile, got %v", err)
+	}
+	if err := MoveFile(p, filepath.Join(filepath.Dir(p), ".", "x.txt")); !errors.Is(err, ErrSameFile) {
+		t.Fatalf("expected ErrSameFile, got %v", err)
+	}
+	if data, _ := os.ReadFile(p); string(data) != "content" {
+		t.Fatalf("content changed to %q", data)
+	}
+}
+
+// TestDeepCompareMmapPause - the memory mapped compare waits while the package controller is paused
+func TestDeepCompareMmapPause(t *testing.T) {
+	dir := t.TempDir()
+	data := make([]byte, 4*chunkSize)
+	f1, f2 := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	os.WriteFile(f1, data, 0o644)
+	os.WriteFile(f2, data, 0o644)
+	old := MmapThreshold
+	MmapThreshold = 1
+	defer func() { MmapThreshold = old }()
+	p := NewPauseController()
+	SetPauseController(p)
+	defer SetPauseController(nil)
+	p.Pause()
+	done := make(chan bool)
+	go func() { done <- DeepCompare(f1, f2) }()
+	select {
+	case <-done:
+		t.Fatal("DeepCompare did not wait while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+	p.Resume()
+	if !<-done {
+		t.Fatal("same files compared as different")
+	}
+}
+
+// TestGzipExtractConcatenated - all the members of a concatenated gzip file are extracted
+func TestGzipExtractConcatenated(t *testing.T) {
+	dir := t.TempDir()
+	first, err := GzipBytes([]byte("first member\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := GzipBytes([]byte("second member\n"))
+	src := filepath.Join(dir, "log.gz")
+	os.WriteFile(src, append(first, second...), 0o644)
+	dest := filepath.Join(dir, "log.txt")
+	if err = GzipExtract(src, dest); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "first member\nsecond member\n" {
+		t.Fatalf("extracted %q", data)
+	}
+}
+
+// TestNextAvailableNameDotfile - the leading dot of a dotfile is part of the name, not an extension
+func TestNextAvailableNameDotfile(t *testing.T) {
+	d := t.TempDir()
+	for _, n := range []string{".bashrc", "a.txt", ".config.json"} {
+		os.WriteFile(filepath.Join(d, n), nil, 0o644)
+	}
+	for n, want := range map[string]string{".bashrc": ".bashrc (1)", "a.txt": "a (1).txt", ".config.json": ".config (1).json"} {
+		got, err := NextAvailableName(filepath.Join(d, n))
+		if err != nil || got != filepath.Join(d, want) {
+			t.Fatalf("%s: got %q %v, want %q", n, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package razutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+/*
+Group - run a set of goroutines and wait for all of them, like errgroup but:
+  - a panic in a goroutine is recovered and returned as an error (PanicError)
+  - all the errors are collected and returned joined by Wait, not only the first one
+  - SetLimit caps the number of goroutines running at once (Go blocks until there is room)
+
+A group made by NewGroup cancels its context on the first error, the zero Group is ready to use with no context.
+*/
+
+// PanicError - a recovered panic, with the stack of the goroutine that panicked
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n%s", e.Value, e.Stack)
+}
+
+// recoverError - to be deferred, turns a panic into a *PanicError stored in err
+func recoverError(err *error) {
+	if r := recover(); r != nil {
+		*err = &PanicError{Value: r, Stack: debug.Stack()}
+	}
+}
+
+type Group struct {
+	cancel context.CancelCauseFunc
+	sem    chan struct{}
+	errs   []error
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+}
+
+// NewGroup - create a group and a context derived from ctx that is cancelled on the first error or when Wait returns
+func NewGroup(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Group{cancel: cancel}, ctx
+}
+
+// SetLimit - allow at most n goroutines at once (n <= 0 is no limit). must not be called while goroutines are running
+func (g *Group) SetLimit(n int) {
+	if n <= 0 {
+		g.sem = nil
+		return
+	}
+	if len(g.sem) != 0 {
+		panic("group: SetLimit while goroutines are running")
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Go - run fn in a new goroutine, blocking first while the limit is reached
+func (g *Group) Go(fn func() error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.start(fn)
+}
+
+// TryGo - run fn in a new goroutine only if the limit is not reached, returns false if it was not started
+func (g *Group) TryGo(fn func() error) bool {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		default:
+			return false
+		}
+	}
+	g.start(fn)
+	return true
+}
+
+func (g *Group) start(fn func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := safeRun(fn); err != nil {
+			g.mu.Lock()
+			g.errs = append(g.errs, err)
+			g.mu.Unlock()
+			if g.cancel != nil {
+				g.cancel(err)
+			}
+		}
+		if g.sem != nil {
+			<-g.sem
+		}
+	}()
+}
+
+// safeRun - call fn converting a panic into an error
+func safeRun(fn func() error) (err error) {
+	defer recoverError(&err)
+	return fn()
+}
+
+// Wait - wait for all the goroutines and return their errors joined (nil if all succeeded)
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel(nil)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return errors.Join(g.errs...)
+}
@@ -0,0 +1,46 @@
+package razutils
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// FileSHA256 - return the hex sha256 of a file content.
+func FileSHA256(path string) (string, error) {
+	h := sha256.New()
+	if err := hashFile(path, h); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// FileXXHash64 - return the xxHash64 of a file content. xxHash is much faster than sha256 and is good for change
+// detection and duplicate pre-filtering, but it is not a cryptographic hash.
+func FileXXHash64(path string) (uint64, error) {
+	h := xxhash.New()
+	if err := hashFile(path, h); err != nil {
+		return 0, err
+	}
+	return h.Sum64(), nil
+}
+
+// FileFingerprint128 - return a 128 bit fingerprint of a file content, for when 64 bit collisions are a concern
+// (very large file sets).  It is two xxHash64 sums with different seeds taken in one read, it is not XXH3-128 and
+// matches no other tool (xxh128sum etc.), compare it only with other results of this function.
+func FileFingerprint128(path string) ([16]byte, error) {
+	var sum [16]byte
+	h1, h2 := xxhash.NewWithSeed(0), xxhash.NewWithSeed(fingerprintSeed)
+	if err := hashFile(path, io.MultiWriter(h1, h2)); err != nil {
+		return sum, err
+	}
+	binary.BigEndian.PutUint64(sum[:8], h1.Sum64())
+	binary.BigEndian.PutUint64(sum[8:], h2.Sum64())
+	return sum, nil
+}
+
+// fingerprintSeed - the seed of the second half of FileFingerprint128
+const fingerprintSeed = 0x9e3779b97f4a7c15
@@ -0,0 +1,41 @@
+package razutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSameFileHash - files of the same size are compared by content when hashing
+func TestSameFileHash(t *testing.T) {
+	d := t.TempDir()
+	write := func(name string, data string) (string, os.FileInfo) {
+		p := filepath.Join(d, name)
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		fi, _ := os.Stat(p)
+		return p, fi
+	}
+	pa, fa := write("a", "same content")
+	pb, fb := write("b", "same content")
+	pc, fc := write("c", "other conten")
+	for _, hash := range []fileHash{xxHashString, FileSHA256} {
+		if same, err := sameFile(pa, fa, pb, fb, hash); err != nil || !same {
+			t.Fatalf("equal files: %v %v", same, err)
+		}
+		if same, err := sameFile(pa, fa, pc, fc, hash); err != nil || same {
+			t.Fatalf("different files: %v %v", same, err)
+		}
+	}
+	h1, err := FileFingerprint128(pa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h2, _ := FileFingerprint128(pb); h1 != h2 {
+		t.Fatal("equal files hash differently")
+	}
+	if h3, _ := FileFingerprint128(pc); h1 == h3 {
+		t.Fatal("different files hash the same")
+	}
+}
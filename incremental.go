@@ -0,0 +1,176 @@
+package razutils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+/*
+Incremental tars for backups: a Snapshot records the state of the files of a tree when an archive is made, the next
+archive holds only the files changed since (and the list of the deleted ones).  Restoring extracts the full archive
+and then each increment in order.
+*/
+
+// incrementalDeleted - the pax global record of an incremental tar listing the files deleted since the previous
+// snapshot (JSON).  It is not a file entry, so it can not collide with a file of the tree.
+const incrementalDeleted = "RAZUTILS.deleted"
+
+// SnapshotFile - the recorded state of a file
+type SnapshotFile struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	Hash    uint64    `json:"hash"` // xxHash64 of the content
+}
+
+// Snapshot - the state of the files under Root when an archive was made, by their slash separated path relative to
+// Root
+type Snapshot struct {
+	Root  string                  `json:"root"`
+	Time  time.Time               `json:"time"`
+	Files map[string]SnapshotFile `json:"files"`
+}
+
+// Save - save the snapshot into a JSON file (atomically)
+func (s *Snapshot) Save(path string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data, 0644)
+}
+
+// LoadSnapshot - load a snapshot saved by Save
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(LongPath(path))
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{}
+	if err = json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.Files == nil {
+		s.Files = map[string]SnapshotFile{}
+	}
+	return s, nil
+}
+
+// TarCreateIncremental - create a tar at dest (compressed by its extension as for TarCreate) of the regular files
+// under root, stored by their path relative to root, and return the snapshot of the tree to save for the next run.
+// With a nil prev all the files are archived (a full backup), otherwise only those changed since prev: a file with
+// the same size and mtime is taken as unchanged, else its content hash decides.  The files deleted since prev are
+// listed in the archive for RestoreIncremental.
+func TarCreateIncremental(dest string, root string, prev *Snapshot, opts ...ArchiveOption) (*Snapshot, error) {
+	cfg := archiveOptions(opts)
+	root = filepath.Clean(root)
+	snap := &Snapshot{Root: absClean(root), Time: time.Now(), Files: map[string]SnapshotFile{}}
+	destAbs := absClean(dest)
+	var files []archiveFile
+	for p, err := range WalkFiles(context.Background(), root, nil) {
+		if err != nil {
+			return nil, err
+		}
+		if isArchiveOrPart(p, destAbs) {
+			continue
+		}
+		rel, err := filepath.Rel(LongPath(root), p)
+		if err != nil {
+			return nil, err
+		}
+		name := filepath.ToSlash(rel)
+		info, err := os.Lstat(p)
+		if err != nil {
+			return nil, err
+		}
+		cur := SnapshotFile{Size: info.Size(), ModTime: info.ModTime()}
+		var old SnapshotFile
+		had := false
+		if prev != nil {
+			old, had = prev.Files[name]
+		}
+		if had && old.Size == cur.Size && old.ModTime.Equal(cur.ModTime) {
+			cur.Hash = old.Hash
+			snap.Files[name] = cur
+			continue
+		}
+		if cur.Hash, err = FileXXHash64(p); err != nil {
+			return nil, err
+		}
+		snap.Files[name] = cur
+		if had && old.Size == cur.Size && old.Hash == cur.Hash {
+			continue // touched only
+		}
+		files = append(files, archiveFile{path: p, name: name, info: info})
+	}
+	var global map[string]string
+	if prev != nil {
+		var deleted []string
+		for name := range prev.Files {
+			if _, ok := snap.Files[name]; !ok {
+				deleted = append(deleted, name)
+			}
+		}
+		if len(deleted) > 0 {
+			slices.Sort(deleted)
+			data, err := json.Marshal(deleted)
+			if err != nil {
+				return nil, err
+			}
+			global = map[string]string{incrementalDeleted: string(data)}
+		}
+	}
+	if err := tarWrite(dest, files, cfg, global); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// RestoreIncremental - restore into destDir the full archive and then the increments made by TarCreateIncremental,
+// archives are given in the order they were made.  The files deleted between the increments are removed.
+func RestoreIncremental(destDir string, archives []string, opts ...ArchiveOption) error {
+	for _, a := range archives {
+		var deleted string
+		opt := func(c *archiveConfig) {
+			c.paxGlobal = func(records map[string]string) {
+				if list, ok := records[incrementalDeleted]; ok {
+					deleted = list
+				}
+			}
+		}
+		if err := TarExtract(a, destDir, append(slices.Clip(opts), opt)...); err != nil {
+			return fmt.Errorf("%s: %w", a, err)
+		}
+		if err := applyDeleted(destDir, deleted); err != nil {
+			return fmt.Errorf("%s: %w", a, err)
+		}
+	}
+	return nil
+}
+
+// applyDeleted - remove the files in the list of deleted files of an increment (JSON, empty if there is none)
+func applyDeleted(destDir string, list string) error {
+	if list == "" {
+		return nil
+	}
+	var deleted []string
+	if err := json.Unmarshal([]byte(list), &deleted); err != nil {
+		return err
+	}
+	for _, name := range deleted {
+		target, err := entryPath(destDir, name, false)
+		if err != nil {
+			return err
+		}
+		if err = os.Remove(LongPath(target)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package razutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRestoreIncrementalUserFile - a file of the tree named as the package metadata is backed up and restored like
+// any other, and the deleted files list still applies
+func TestRestoreIncrementalUserFile(t *testing.T) {
+	d := t.TempDir()
+	root := filepath.Join(d, "root")
+	os.MkdirAll(root, 0o755)
+	user := filepath.Join(root, ".razutils-deleted")
+	os.WriteFile(user, []byte("mine"), 0o644)
+	os.WriteFile(filepath.Join(root, "gone.txt"), []byte("x"), 0o644)
+	full := filepath.Join(d, "full.tar")
+	snap, err := TarCreateIncremental(full, root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(filepath.Join(root, "gone.txt"))
+	os.WriteFile(user, []byte("[\"a\"]"), 0o644)
+	inc := filepath.Join(d, "inc.tar")
+	if _, err = TarCreateIncremental(inc, root, snap); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(d, "out")
+	if err = RestoreIncremental(out, []string{full, inc}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(out, ".razutils-deleted")); string(data) != "[\"a\"]" {
+		t.Fatalf("user file restored as %q", data)
+	}
+	if _, err = os.Stat(filepath.Join(out, "gone.txt")); err == nil {
+		t.Fatal("deleted file restored")
+	}
+}
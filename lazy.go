@@ -0,0 +1,98 @@
+package razutils
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+LazyValue - a value computed on the first Get, e.g. opening a database or loading a config once in a daemon.
+A successful result is cached for good.  By default a failure is cached as well (like sync.OnceValues), with
+RetryAfterFailure the next Get tries again once a backoff passes, doubling on every consecutive failure.
+Concurrent Gets while computing wait for the single computation.
+*/
+
+type LazyValue[T any] struct {
+	fn        func() (T, error)
+	val       atomic.Pointer[T] // set once computed, the fast path reads it with no lock (Reset may clear it anytime)
+	err       error
+	failed    bool
+	failures  int
+	retryAt   time.Time
+	retry     bool
+	base, max time.Duration
+	mu        sync.Mutex
+}
+
+// NewLazyValue - create a lazy value computed by fn
+func NewLazyValue[T any](fn func() (T, error)) *LazyValue[T] {
+	return &LazyValue[T]{fn: fn}
+}
+
+// RetryAfterFailure - make Get call fn again after a failure, but not before base has passed (doubling on each
+// consecutive failure up to maxWait).  Until then Get returns the last error.  a base of 0 retries on every Get.
+func (l *LazyValue[T]) RetryAfterFailure(base, maxWait time.Duration) *LazyValue[T] {
+	l.mu.Lock()
+	l.retry, l.base, l.max = true, base, max(maxWait, base)
+	l.mu.Unlock()
+	return l
+}
+
+// Get - return the value, computing it if needed
+func (l *LazyValue[T]) Get() (T, error) {
+	if v := l.val.Load(); v != nil {
+		return *v, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if v := l.val.Load(); v != nil {
+		return *v, nil
+	}
+	if l.failed && (!l.retry || time.Now().Before(l.retryAt)) {
+		var zero T
+		return zero, l.err
+	}
+	v, err := l.call()
+	if err != nil {
+		l.failed, l.err = true, err
+		l.failures++
+		wait := l.max
+		if l.failures < 62 && l.base <= l.max>>(l.failures-1) {
+			wait = l.base << (l.failures - 1)
+		}
+		l.retryAt = time.Now().Add(wait)
+		return v, err
+	}
+	l.err, l.failed, l.failures = nil, false, 0
+	l.val.Store(&v)
+	return v, nil
+}
+
+// call - run fn turning a panic into an error, so a panicking init does not leave the value locked
+func (l *LazyValue[T]) call() (v T, err error) {
+	defer recoverError(&err)
+	return l.fn()
+}
+
+// MustGet - Get panicking on error
+func (l *LazyValue[T]) MustGet() T {
+	v, err := l.Get()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Loaded - check if the value was computed successfully
+func (l *LazyValue[T]) Loaded() bool {
+	return l.val.Load() != nil
+}
+
+// Reset - forget the value (or the failure) so the next Get computes it again
+func (l *LazyValue[T]) Reset() {
+	l.mu.Lock()
+	l.val.Store(nil)
+	l.err, l.failed, l.failures = nil, false, 0
+	l.mu.Unlock()
+}
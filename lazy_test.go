@@ -0,0 +1,32 @@
+package razutils
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestLazyValueResetRace - Get and Reset running together (go test -race checks the access to the value)
+func TestLazyValueResetRace(t *testing.T) {
+	n := 0
+	l := NewLazyValue(func() ([]int, error) {
+		n++ // fn runs under the lock
+		return []int{n}, nil
+	})
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if v, err := l.Get(); err != nil || len(v) != 1 {
+					t.Error(v, err)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		l.Reset()
+	}
+	wg.Wait()
+}
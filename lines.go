@@ -0,0 +1,47 @@
+package razutils
+
+import (
+	"os"
+	"strings"
+)
+
+// splitLines - split text into lines accepting LF, CRLF and CR line endings. a final line ending does not create an
+// extra empty line.
+func splitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(text, "\n")
+}
+
+// ReadLines - read a text file into lines. BOMs are removed, CRLF/CR line endings are normalized and non utf-8
+// content is transcoded (see ReadFileUTF8).
+func ReadLines(path string) ([]string, error) {
+	text, _, err := ReadFileUTF8(path)
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(text), nil
+}
+
+// WriteLines - write lines into a utf-8 text file (no BOM), each line terminated by LF.
+func WriteLines(path string, lines []string) error {
+	return writeLines(path, lines, "\n")
+}
+
+// WriteLinesCRLF - same as WriteLines but with windows CRLF line endings.
+func WriteLinesCRLF(path string, lines []string) error {
+	return writeLines(path, lines, "\r\n")
+}
+
+func writeLines(path string, lines []string, eol string) error {
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString(l)
+		b.WriteString(eol)
+	}
+	return os.WriteFile(LongPath(path), []byte(b.String()), 0644)
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package razutils
+
+// LongPath - on windows return the path in the \\?\ form needed for long paths. other systems have no such limit
+// and the path is returned as is.
+func LongPath(path string) string {
+	return path
+}
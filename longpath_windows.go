@@ -0,0 +1,28 @@
+//go:build windows
+
+package razutils
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// maxShortPath - paths at or above this length get the \\?\ prefix. it is below MAX_PATH (260) since directories
+// must leave room for an 8.3 file name.
+const maxShortPath = 248
+
+// LongPath - return the path in the \\?\ (or \\?\UNC\) form needed by the windows API for paths longer than
+// 260 characters.  Short paths and paths already in the extended form are returned as is.
+func LongPath(path string) string {
+	if len(path) < maxShortPath || strings.HasPrefix(path, `\\?\`) || strings.HasPrefix(path, `\\.\`) {
+		return path
+	}
+	abs, err := filepath.Abs(path) // the extended form does not allow relative paths, . or ..
+	if err != nil {
+		return path
+	}
+	if strings.HasPrefix(abs, `\\`) { // UNC: \\server\share\... => \\?\UNC\server\share\...
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
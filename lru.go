@@ -0,0 +1,106 @@
+package razutils
+
+import (
+	"container/list"
+	"sync"
+)
+
+/*
+LRUCache - a thread safe fixed capacity cache evicting the least recently used entry when full.
+An optional callback is called for evicted entries (not for explicit Remove), and hits/misses are counted.
+*/
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+type LRUCache[K comparable, V any] struct {
+	capacity int
+	ll       *list.List // front is the most recently used
+	items    map[K]*list.Element
+	onEvict  func(K, V)
+	hits     int
+	misses   int
+	mu       sync.Mutex
+}
+
+// NewLRUCache - create a cache holding up to capacity entries (at least 1). onEvict may be nil.
+func NewLRUCache[K comparable, V any](capacity int, onEvict func(key K, value V)) *LRUCache[K, V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LRUCache[K, V]{capacity: capacity, ll: list.New(), items: make(map[K]*list.Element), onEvict: onEvict}
+}
+
+// Get - return the value of key, marking it as recently used
+func (c *LRUCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		c.hits++
+		return el.Value.(*lruEntry[K, V]).value, true
+	}
+	c.misses++
+	var zero V
+	return zero, false
+}
+
+// Put - add or replace the value of key, evicting the least recently used entry if the cache is full
+func (c *LRUCache[K, V]) Put(key K, value V) {
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruEntry[K, V]).value = value
+		c.ll.MoveToFront(el)
+		c.mu.Unlock()
+		return
+	}
+	c.items[key] = c.ll.PushFront(&lruEntry[K, V]{key: key, value: value})
+	var evicted *lruEntry[K, V]
+	if c.ll.Len() > c.capacity {
+		evicted = c.ll.Remove(c.ll.Back()).(*lruEntry[K, V])
+		delete(c.items, evicted.key)
+	}
+	c.mu.Unlock()
+	if evicted != nil && c.onEvict != nil {
+		c.onEvict(evicted.key, evicted.value) // outside the lock, so the callback may use the cache
+	}
+}
+
+// Remove - delete key from the cache. returns false if it was not there
+func (c *LRUCache[K, V]) Remove(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	c.ll.Remove(el)
+	delete(c.items, key)
+	return true
+}
+
+// Len - return the number of entries in the cache
+func (c *LRUCache[K, V]) Len() int {
+	c.mu.Lock()
+	res := c.ll.Len()
+	c.mu.Unlock()
+	return res
+}
+
+// Stats - return the number of Get hits and misses
+func (c *LRUCache[K, V]) Stats() (hits int, misses int) {
+	c.mu.Lock()
+	hits, misses = c.hits, c.misses
+	c.mu.Unlock()
+	return hits, misses
+}
+
+// Clear - remove all entries (without calling the eviction callback)
+func (c *LRUCache[K, V]) Clear() {
+	c.mu.Lock()
+	c.ll.Init()
+	clear(c.items)
+	c.mu.Unlock()
+}
@@ -0,0 +1,151 @@
+package razutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+/*
+AtomicCounter and Gauge - lock free int64 metrics, and a Registry naming them so a status endpoint can dump all the
+values at once:
+
+	reg := NewRegistry()
+	files := reg.Counter("scan.files")
+	files.Inc()
+	q.RegisterMetrics(reg, "jobs")
+	http.Handle("/metrics", reg)   // {"jobs.len":3, "scan.files":1, ...}
+
+A counter only goes up (until Reset), a gauge is a current value that goes up and down.
+(Counter is the frequency counter in counter.go, hence the AtomicCounter name.)
+*/
+
+// Metric - anything with an int64 value that can be registered
+type Metric interface {
+	Load() int64
+}
+
+// AtomicCounter - a monotonic counter, the zero value is ready to use
+type AtomicCounter struct {
+	v atomic.Int64
+}
+
+// Inc - add 1
+func (c *AtomicCounter) Inc() { c.v.Add(1) }
+
+// Add - add n (should not be negative)
+func (c *AtomicCounter) Add(n int64) { c.v.Add(n) }
+
+// Load - return the value
+func (c *AtomicCounter) Load() int64 { return c.v.Load() }
+
+// Reset - set back to 0 returning the value it had
+func (c *AtomicCounter) Reset() int64 { return c.v.Swap(0) }
+
+// Gauge - a value that can go up and down, the zero value is ready to use
+type Gauge struct {
+	v atomic.Int64
+}
+
+// Set - set the value
+func (g *Gauge) Set(n int64) { g.v.Store(n) }
+
+// Add - add n (may be negative)
+func (g *Gauge) Add(n int64) { g.v.Add(n) }
+
+// Inc - add 1
+func (g *Gauge) Inc() { g.v.Add(1) }
+
+// Dec - subtract 1
+func (g *Gauge) Dec() { g.v.Add(-1) }
+
+// Load - return the value
+func (g *Gauge) Load() int64 { return g.v.Load() }
+
+// MetricFunc - a metric computed when read, e.g. a queue length
+type MetricFunc func() int64
+
+// Load - call the function
+func (f MetricFunc) Load() int64 { return f() }
+
+// Registry - a named set of metrics
+type Registry struct {
+	metrics map[string]Metric
+	mu      sync.RWMutex
+}
+
+// DefaultRegistry - a registry for programs that need just one
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry - create an empty registry
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]Metric)}
+}
+
+// Register - add a metric under name, replacing any metric already registered with that name
+func (r *Registry) Register(name string, m Metric) {
+	r.mu.Lock()
+	r.metrics[name] = m
+	r.mu.Unlock()
+}
+
+// Unregister - remove a metric
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	delete(r.metrics, name)
+	r.mu.Unlock()
+}
+
+// Counter - return the counter registered under name, creating it if needed.  panics if name is registered as
+// another kind of metric.
+func (r *Registry) Counter(name string) *AtomicCounter {
+	return getOrRegister(r, name, func() *AtomicCounter { return &AtomicCounter{} })
+}
+
+// Gauge - return the gauge registered under name, creating it if needed. panics if name is registered as another
+// kind of metric.
+func (r *Registry) Gauge(name string) *Gauge {
+	return getOrRegister(r, name, func() *Gauge { return &Gauge{} })
+}
+
+// Func - register a metric computed by fn on every read
+func (r *Registry) Func(name string, fn func() int64) {
+	r.Register(name, MetricFunc(fn))
+}
+
+func getOrRegister[M Metric](r *Registry, name string, create func() M) M {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if m, ok := r.metrics[name]; ok {
+		typed, ok := m.(M)
+		if !ok {
+			panic(fmt.Sprintf("metric %s already registered as %T", name, m))
+		}
+		return typed
+	}
+	m := create()
+	r.metrics[name] = m
+	return m
+}
+
+// Snapshot - return the current values of all the metrics
+func (r *Registry) Snapshot() map[string]int64 {
+	r.mu.RLock()
+	metrics := maps.Clone(r.metrics)
+	r.mu.RUnlock()
+	// read outside the lock, a MetricFunc may take other locks
+	res := make(map[string]int64, len(metrics))
+	for name, m := range metrics {
+		res[name] = m.Load()
+	}
+	return res
+}
+
+// ServeHTTP - write the snapshot as a JSON object (keys sorted), so the registry can be mounted as a status endpoint
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(r.Snapshot())
+}
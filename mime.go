@@ -0,0 +1,80 @@
+package razutils
+
+import (
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// mediaTypes - media types by extension for the types the system mime tables often miss or disagree on
+var mediaTypes = map[string]string{
+	".mkv":  "video/x-matroska",
+	".webm": "video/webm",
+	".mp4":  "video/mp4",
+	".m4v":  "video/x-m4v",
+	".mov":  "video/quicktime",
+	".avi":  "video/x-msvideo",
+	".mpg":  "video/mpeg",
+	".mpeg": "video/mpeg",
+	".ts":   "video/mp2t",
+	".flv":  "video/x-flv",
+	".wmv":  "video/x-ms-wmv",
+	".ogg":  "video/ogg",
+	".srt":  "application/x-subrip",
+	".vtt":  "text/vtt",
+	".ass":  "text/x-ssa",
+	".ssa":  "text/x-ssa",
+	".nfo":  "text/plain; charset=utf-8",
+}
+
+// fileTypeMime - media types for the content types detected by DetectFileType
+var fileTypeMime = map[FileType]string{
+	FileTypeMKV:    "video/x-matroska",
+	FileTypeWebM:   "video/webm",
+	FileTypeMP4:    "video/mp4",
+	FileTypeMOV:    "video/quicktime",
+	FileTypeAVI:    "video/x-msvideo",
+	FileTypeMPEG:   "video/mpeg",
+	FileTypeMPEGTS: "video/mp2t",
+	FileTypeFLV:    "video/x-flv",
+	FileTypeASF:    "video/x-ms-asf",
+	FileTypeOGG:    "application/ogg",
+	FileTypeWAV:    "audio/wav",
+	FileTypeMP3:    "audio/mpeg",
+	FileTypeGzip:   "application/gzip",
+	FileTypeZip:    "application/zip",
+	FileTypeBzip2:  "application/x-bzip2",
+	FileTypeXz:     "application/x-xz",
+	FileTypeZstd:   "application/zstd",
+	FileType7z:     "application/x-7z-compressed",
+	FileTypeRar:    "application/vnd.rar",
+	FileTypeTar:    "application/x-tar",
+	FileTypePDF:    "application/pdf",
+	FileTypePNG:    "image/png",
+	FileTypeJPEG:   "image/jpeg",
+	FileTypeGIF:    "image/gif",
+}
+
+// MimeTypeOf - return the media (MIME) type of a file, e.g. "video/x-matroska".  The extension is used first, if it
+// is missing or unknown the file content is sniffed.  "application/octet-stream" is returned when nothing matched.
+// An error is returned only if the content had to be read and could not be.
+func MimeTypeOf(path string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != "" {
+		if t, ok := mediaTypes[ext]; ok {
+			return t, nil
+		}
+		if t := mime.TypeByExtension(ext); t != "" {
+			return t, nil
+		}
+	}
+	h, err := readHeader(path, sniffLen)
+	if err != nil {
+		return "", err
+	}
+	if t, ok := fileTypeMime[detectType(h)]; ok {
+		return t, nil
+	}
+	return http.DetectContentType(h), nil // falls back to application/octet-stream itself
+}
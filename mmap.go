@@ -0,0 +1,62 @@
+package razutils
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// MmapThreshold - files of at least this size are memory mapped by DeepCompare and the file hash functions instead
+// of being read in chunks.  This is much faster on local SSD/NVMe drives but may be slower (or fail) on network
+// shares, so it is disabled (0) by default.
+var MmapThreshold int64 = 0
+
+// MmapFile - map a file read-only into memory.  The returned func unmaps it, the data must not be used after that.
+// An empty file returns an empty slice.
+func MmapFile(path string) ([]byte, func() error, error) {
+	f, err := os.Open(LongPath(path))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close() // the mapping stays valid after the file is closed
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.Size() == 0 {
+		return []byte{}, func() error { return nil }, nil
+	}
+	return mmapFile(f, info.Size())
+}
+
+// useMmap - check if the file of the given size should be mapped rather than read
+func useMmap(size int64) bool {
+	return MmapThreshold > 0 && size >= MmapThreshold && int64(int(size)) == size
+}
+
+// hashFile - feed the file content into h, memory mapped for large files when enabled, otherwise by reading it
+func hashFile(path string, h io.Writer) error {
+	f, err := os.Open(LongPath(path))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err == nil && useMmap(info.Size()) {
+		data, unmap, err := mmapFile(f, info.Size())
+		if err == nil {
+			defer unmap()
+			for len(data) > 0 {
+				if err := pauseWait(context.Background()); err != nil {
+					return err
+				}
+				n := min(len(data), chunkSize)
+				h.Write(data[:n])
+				data = data[n:]
+			}
+			return nil
+		}
+		// mapping failed (e.g. not supported by the filesystem), fall back to reading
+	}
+	_, err = io.Copy(h, pausable(f))
+	return err
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package razutils
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// mmapFile - map size bytes of an open file read-only
+func mmapFile(f *os.File, size int64) ([]byte, func() error, error) {
+	data, err := unix.Mmap(int(f.Fd()), 0, int(size), unix.PROT_READ, unix.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return unix.Munmap(data) }, nil
+}
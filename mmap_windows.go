@@ -0,0 +1,25 @@
+//go:build windows
+
+package razutils
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// mmapFile - map size bytes of an open file read-only
+func mmapFile(f *os.File, size int64) ([]byte, func() error, error) {
+	h, err := syscall.CreateFileMapping(syscall.Handle(f.Fd()), nil, syscall.PAGE_READONLY,
+		uint32(size>>32), uint32(size), nil)
+	if err != nil {
+		return nil, nil, os.NewSyscallError("CreateFileMapping", err)
+	}
+	addr, err := syscall.MapViewOfFile(h, syscall.FILE_MAP_READ, 0, 0, uintptr(size))
+	syscall.CloseHandle(h) // the view keeps the mapping alive
+	if err != nil {
+		return nil, nil, os.NewSyscallError("MapViewOfFile", err)
+	}
+	data := unsafe.Slice((*byte)(unsafe.Add(nil, addr)), int(size)) // addr is memory outside the go heap
+	return data, func() error { return syscall.UnmapViewOfFile(addr) }, nil
+}
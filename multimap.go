@@ -0,0 +1,97 @@
+package razutils
+
+import (
+	"slices"
+	"sync"
+)
+
+/*
+MultiMap - a thread safe map of one key to many values (e.g. show name to its episode files).
+Values of a key keep the order they were added in.
+*/
+
+type MultiMap[K comparable, V comparable] struct {
+	items map[K][]V
+	mu    sync.RWMutex
+}
+
+// NewMultiMap - create an empty MultiMap
+func NewMultiMap[K comparable, V comparable]() *MultiMap[K, V] {
+	return &MultiMap[K, V]{items: make(map[K][]V)}
+}
+
+// Add - add values to key
+func (m *MultiMap[K, V]) Add(key K, values ...V) {
+	m.mu.Lock()
+	m.items[key] = append(m.items[key], values...)
+	m.mu.Unlock()
+}
+
+// GetAll - return a copy of the values of key (nil if the key is not in the map)
+func (m *MultiMap[K, V]) GetAll(key K) []V {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Clone(m.items[key])
+}
+
+// Has - check if key has any values
+func (m *MultiMap[K, V]) Has(key K) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.items[key]) > 0
+}
+
+// RemoveValue - remove the first occurrence of value from key, the key is removed when it has no values left.
+// returns false if the value was not found.
+func (m *MultiMap[K, V]) RemoveValue(key K, value V) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	vals := m.items[key]
+	i := slices.Index(vals, value)
+	if i == -1 {
+		return false
+	}
+	vals = slices.Delete(vals, i, i+1)
+	if len(vals) == 0 {
+		delete(m.items, key)
+	} else {
+		m.items[key] = vals
+	}
+	return true
+}
+
+// RemoveKey - remove a key with all its values
+func (m *MultiMap[K, V]) RemoveKey(key K) {
+	m.mu.Lock()
+	delete(m.items, key)
+	m.mu.Unlock()
+}
+
+// Keys - return the keys (in no specific order)
+func (m *MultiMap[K, V]) Keys() []K {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := make([]K, 0, len(m.items))
+	for k := range m.items {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Len - return the number of keys
+func (m *MultiMap[K, V]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.items)
+}
+
+// Count - return the total number of values of all the keys
+func (m *MultiMap[K, V]) Count() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	cnt := 0
+	for _, v := range m.items {
+		cnt += len(v)
+	}
+	return cnt
+}
@@ -0,0 +1,153 @@
+package razutils
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"sync"
+)
+
+/*
+OrderedMap - a thread safe map that keeps its keys in insertion order (setting an existing key keeps its place).
+Keys can be moved to the front or the back.  It marshals to a JSON object with the keys in order, which a plain Go
+map can not do.
+*/
+
+type omEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+type OrderedMap[K comparable, V any] struct {
+	ll    *list.List
+	items map[K]*list.Element
+	mu    sync.RWMutex
+}
+
+// NewOrderedMap - create an empty ordered map
+func NewOrderedMap[K comparable, V any]() *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{ll: list.New(), items: make(map[K]*list.Element)}
+}
+
+// Set - set the value of key. a new key is added at the back, an existing one keeps its position
+func (m *OrderedMap[K, V]) Set(key K, value V) {
+	m.mu.Lock()
+	if el, ok := m.items[key]; ok {
+		el.Value.(*omEntry[K, V]).value = value
+	} else {
+		m.items[key] = m.ll.PushBack(&omEntry[K, V]{key: key, value: value})
+	}
+	m.mu.Unlock()
+}
+
+// Get - return the value of key
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if el, ok := m.items[key]; ok {
+		return el.Value.(*omEntry[K, V]).value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Delete - remove key. returns false if it was not in the map
+func (m *OrderedMap[K, V]) Delete(key K) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.items[key]
+	if !ok {
+		return false
+	}
+	m.ll.Remove(el)
+	delete(m.items, key)
+	return true
+}
+
+// MoveToFront - move key to be the first in order. returns false if it is not in the map
+func (m *OrderedMap[K, V]) MoveToFront(key K) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.items[key]
+	if ok {
+		m.ll.MoveToFront(el)
+	}
+	return ok
+}
+
+// MoveToBack - move key to be the last in order. returns false if it is not in the map
+func (m *OrderedMap[K, V]) MoveToBack(key K) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.items[key]
+	if ok {
+		m.ll.MoveToBack(el)
+	}
+	return ok
+}
+
+// Len - return the number of keys
+func (m *OrderedMap[K, V]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.ll.Len()
+}
+
+// Keys - return the keys in order
+func (m *OrderedMap[K, V]) Keys() []K {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := make([]K, 0, m.ll.Len())
+	for el := m.ll.Front(); el != nil; el = el.Next() {
+		keys = append(keys, el.Value.(*omEntry[K, V]).key)
+	}
+	return keys
+}
+
+// snapshot - copy of the entries in order
+func (m *OrderedMap[K, V]) snapshot() []omEntry[K, V] {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entries := make([]omEntry[K, V], 0, m.ll.Len())
+	for el := m.ll.Front(); el != nil; el = el.Next() {
+		entries = append(entries, *el.Value.(*omEntry[K, V]))
+	}
+	return entries
+}
+
+// All - return an iterator over a snapshot of the key/value pairs in order: for k, v := range m.All() {...}
+func (m *OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, e := range m.snapshot() {
+			if !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// MarshalJSON - encode as a JSON object keeping the key order. non string keys are formatted with fmt.Sprint.
+func (m *OrderedMap[K, V]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, e := range m.snapshot() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(fmt.Sprint(e.key))
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(e.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
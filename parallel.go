@@ -0,0 +1,36 @@
+package razutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ParallelMap - call fn on all the items using up to workers goroutines and return the results in the input order.
+// all the items are processed even if some fail, the errors are joined (each prefixed by its item index, a panic in
+// fn is a *PanicError).  If ctx is done the items not started yet are skipped and the context error is part of the returned error.
+func ParallelMap[T, R any](ctx context.Context, items []T, workers int, fn func(T) (R, error)) ([]R, error) {
+	res := make([]R, len(items))
+	errs := make([]error, len(items))
+	var g Group
+	g.SetLimit(max(workers, 1))
+	var ctxErr error
+	for i := range items {
+		if ctxErr = ctx.Err(); ctxErr != nil {
+			break
+		}
+		g.Go(func() error {
+			var err error
+			func() {
+				defer recoverError(&err)
+				res[i], err = fn(items[i])
+			}()
+			if err != nil {
+				errs[i] = fmt.Errorf("item %d: %w", i, err)
+			}
+			return nil
+		})
+	}
+	g.Wait()
+	return res, errors.Join(append(errs, ctxErr)...)
+}
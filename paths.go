@@ -0,0 +1,66 @@
+package razutils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// absClean - return the absolute cleaned path, or just the cleaned path if it can not be made absolute
+func absClean(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// escapes - check if a relative path (as returned by filepath.Rel) goes out of its base
+func escapes(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel)
+}
+
+// IsSubPath - check if child is inside parent (or is parent itself).  The check is lexical, done on the absolute
+// cleaned paths, symlinks are not resolved.
+func IsSubPath(parent string, child string) bool {
+	rel, err := filepath.Rel(absClean(parent), absClean(child))
+	if err != nil {
+		return false
+	}
+	return !escapes(rel)
+}
+
+// CommonAncestor - return the deepest directory containing all the given paths (as absolute path).  An empty string
+// is returned if there are no paths or they have no common root (e.g. different drives on windows).  When the paths
+// are all the same existing file (e.g. a single file) its directory is returned.
+func CommonAncestor(paths ...string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	common := absClean(paths[0])
+	for _, p := range paths[1:] {
+		p = absClean(p)
+		for !IsSubPath(common, p) {
+			up := filepath.Dir(common)
+			if up == common {
+				return ""
+			}
+			common = up
+		}
+	}
+	if fi, err := os.Stat(common); err == nil && !fi.IsDir() {
+		return filepath.Dir(common)
+	}
+	return common
+}
+
+// RelOrAbs - return target relative to base when target is inside base, otherwise return the absolute target path.
+// This is what should be stored in library metadata.  Only a relative result can be joined back to base, check
+// filepath.IsAbs before doing so.
+func RelOrAbs(base string, target string) string {
+	abs := absClean(target)
+	rel, err := filepath.Rel(absClean(base), abs)
+	if err != nil || escapes(rel) {
+		return abs
+	}
+	return rel
+}
@@ -0,0 +1,25 @@
+package razutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCommonAncestorFile - a single file gives its directory, not the file itself
+func TestCommonAncestorFile(t *testing.T) {
+	d := t.TempDir()
+	f := filepath.Join(d, "a.txt")
+	if err := os.WriteFile(f, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if c := CommonAncestor(f); c != d {
+		t.Fatalf("got %q, want %q", c, d)
+	}
+	if c := CommonAncestor(f, f); c != d {
+		t.Fatalf("got %q, want %q", c, d)
+	}
+	if c := CommonAncestor(d); c != d {
+		t.Fatalf("got %q, want %q", c, d)
+	}
+}
@@ -0,0 +1,162 @@
+package razutils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+)
+
+/*
+PauseController - cooperative pause/resume for long operations.  Loops that copy, sync or hash data call Wait between
+chunks; while the controller is paused Wait blocks until Resume is called (or the context is done).
+The controller can be driven directly by Pause/Resume, by signals (NotifySignals) or over HTTP (it is an http.Handler).
+A package wide controller can be set with SetPauseController, it is checked by the file helpers of this package
+(CopyFile, MoveFile, DeepCompare and the like).
+*/
+
+type PauseController struct {
+	mu     sync.Mutex
+	paused bool
+	resume chan struct{} // closed when the controller is resumed
+}
+
+// NewPauseController - create a new controller in the running (not paused) state
+func NewPauseController() *PauseController {
+	return &PauseController{}
+}
+
+// Pause - request all the participating loops to pause at their next check point
+func (p *PauseController) Pause() {
+	p.mu.Lock()
+	if !p.paused {
+		p.paused = true
+		p.resume = make(chan struct{})
+	}
+	p.mu.Unlock()
+}
+
+// Resume - let the paused loops continue
+func (p *PauseController) Resume() {
+	p.mu.Lock()
+	if p.paused {
+		p.paused = false
+		close(p.resume)
+	}
+	p.mu.Unlock()
+}
+
+// IsPaused - check if the controller is currently paused
+func (p *PauseController) IsPaused() bool {
+	p.mu.Lock()
+	res := p.paused
+	p.mu.Unlock()
+	return res
+}
+
+// Wait - block while the controller is paused. returns the context error if the context is done while waiting.
+// calling Wait on a nil controller returns immediately.
+func (p *PauseController) Wait(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	if !p.paused {
+		p.mu.Unlock()
+		return nil
+	}
+	ch := p.resume
+	p.mu.Unlock()
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Reader - wrap a reader so each Read first waits while the controller is paused
+func (p *PauseController) Reader(r io.Reader) io.Reader {
+	return &pauseReader{p: p, r: r}
+}
+
+type pauseReader struct {
+	p *PauseController
+	r io.Reader
+}
+
+func (pr *pauseReader) Read(b []byte) (int, error) {
+	if err := pr.p.Wait(context.Background()); err != nil {
+		return 0, err
+	}
+	return pr.r.Read(b)
+}
+
+// NotifySignals - pause when the pause signal is received and resume on the resume signal (e.g. SIGUSR1/SIGUSR2 or
+// SIGTSTP/SIGCONT on unix).  The returned func stops listening.
+func (p *PauseController) NotifySignals(pause os.Signal, resume os.Signal) func() {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, pause, resume)
+	go func() {
+		for {
+			select {
+			case s := <-ch:
+				if s == pause {
+					p.Pause()
+				} else {
+					p.Resume()
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
+
+// ServeHTTP - make the controller usable as a status server endpoint. a GET returns the current state, a POST with
+// action=pause or action=resume (query or form value) changes it.
+func (p *PauseController) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		switch r.FormValue("action") {
+		case "pause":
+			p.Pause()
+		case "resume":
+			p.Resume()
+		default:
+			http.Error(w, "action must be pause or resume", http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	fmt.Fprintf(w, "{\"paused\":%t}\n", p.IsPaused())
+}
+
+var globalPause atomic.Pointer[PauseController]
+
+// SetPauseController - set the package wide controller checked by the file helpers (nil to remove it)
+func SetPauseController(p *PauseController) {
+	globalPause.Store(p)
+}
+
+// pauseWait - wait on the package wide controller, if one was set
+func pauseWait(ctx context.Context) error {
+	return globalPause.Load().Wait(ctx)
+}
+
+// pausable - wrap r with the package wide controller check
+func pausable(r io.Reader) io.Reader {
+	return &pauseReader{p: globalPause.Load(), r: r}
+}
@@ -0,0 +1,90 @@
+package razutils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// gzipBlockSize - the size of the blocks compressed in parallel.  each block restarts the compression dictionary,
+// so too small blocks cost compression ratio; 1MB loses well under 1%.
+const gzipBlockSize = 1 << 20
+
+// GzipParallel - gzip src into dst using workers goroutines.  The input is cut into blocks compressed independently
+// and written in order as consecutive gzip members, which every gzip reader (gunzip, zcat, GzipExtract) reads as a
+// single stream.  hdr (name, modification time) is set on the first member.  Memory use is about 2 blocks per
+// worker.
+func GzipParallel(dst io.Writer, src io.Reader, workers int, level int, hdr gzip.Header) error {
+	workers = max(workers, 1)
+	if _, err := gzip.NewWriterLevel(io.Discard, level); err != nil {
+		return err // a bad level, fail before starting anything
+	}
+	pending := make(chan *Future[[]byte], workers)
+	readErr := make(chan error, 1)
+	stop := make(chan struct{})
+	go func() {
+		defer close(pending)
+		first := true
+		for {
+			block := make([]byte, gzipBlockSize)
+			n, err := io.ReadFull(src, block)
+			if n > 0 || first {
+				h := gzip.Header{}
+				if first {
+					h = hdr
+				}
+				first = false
+				select {
+				case pending <- Go(func() ([]byte, error) { return gzipBlock(block[:n], level, h) }):
+				case <-stop:
+					readErr <- nil
+					return
+				}
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				readErr <- nil
+				return
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+	var werr error
+	for f := range pending {
+		if werr != nil {
+			continue // drain so the reader goroutine ends
+		}
+		member, err := f.Get()
+		if err == nil {
+			_, err = dst.Write(member)
+		}
+		if err != nil {
+			werr = err
+			close(stop)
+		}
+	}
+	if err := <-readErr; werr == nil {
+		werr = err
+	}
+	return werr
+}
+
+// gzipBlock - compress a block as a complete gzip member
+func gzipBlock(block []byte, level int, hdr gzip.Header) ([]byte, error) {
+	var b bytes.Buffer
+	b.Grow(len(block)/2 + 64)
+	w, err := gzip.NewWriterLevel(&b, level)
+	if err != nil {
+		return nil, err
+	}
+	w.Header = hdr
+	if _, err = w.Write(block); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
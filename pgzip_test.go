@@ -0,0 +1,77 @@
+package razutils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+// gzipTestData - compressible text of about size bytes, several blocks long
+func gzipTestData(size int) []byte {
+	words := []string{"queue", "archive", "subtitle", "movie", "backup", "gzip", "worker", "block", "stream"}
+	r := rand.New(rand.NewSource(1))
+	var b bytes.Buffer
+	for b.Len() < size {
+		b.WriteString(words[r.Intn(len(words))])
+		b.WriteByte(" \n"[r.Intn(2)])
+		fmt.Fprint(&b, r.Intn(1000))
+	}
+	return b.Bytes()
+}
+
+// TestGzipParallelRoundTrip - the parallel output reads back as the input through GunzipBytes, with the header on
+// the first member
+func TestGzipParallelRoundTrip(t *testing.T) {
+	data := gzipTestData(2*gzipBlockSize + 1234)
+	for _, workers := range []int{1, 2, 4, 8} {
+		var out bytes.Buffer
+		if err := GzipParallel(&out, bytes.NewReader(data), workers, gzip.DefaultCompression, gzip.Header{Name: "x.txt"}); err != nil {
+			t.Fatal(err)
+		}
+		got, err := GunzipBytes(out.Bytes())
+		if err != nil {
+			t.Fatal(workers, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("%d workers: round trip mismatch (%d bytes, want %d)", workers, len(got), len(data))
+		}
+		if gz, err := gzip.NewReader(&out); err != nil || gz.Name != "x.txt" {
+			t.Fatalf("%d workers: header %v %v", workers, gz, err)
+		}
+	}
+	var out bytes.Buffer
+	if err := GzipParallel(&out, bytes.NewReader(nil), 4, gzip.DefaultCompression, gzip.Header{}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := GunzipBytes(out.Bytes()); err != nil || len(got) != 0 {
+		t.Fatalf("empty input: %q %v", got, err)
+	}
+}
+
+// BenchmarkGzipCompress - the stdlib writer against GzipParallel with 2, 4 and 8 workers
+func BenchmarkGzipCompress(b *testing.B) {
+	data := gzipTestData(8 * gzipBlockSize)
+	b.Run("stdlib", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			w, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+			w.Write(data)
+			if err := w.Close(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	for _, workers := range []int{2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if err := GzipParallel(io.Discard, bytes.NewReader(data), workers, gzip.DefaultCompression, gzip.Header{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
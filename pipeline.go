@@ -0,0 +1,118 @@
+package razutils
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+)
+
+/*
+Pipeline - a chain of stages, each run by its own number of workers and connected to the next by a bounded channel:
+
+	p := NewPipeline[*Job]().
+		Stage(4, probe).
+		Stage(1, rename).
+		Stage(2, move)
+	err := p.Run(ctx, slices.Values(jobs))
+
+A stage returning ErrSkipItem drops the item (it does not reach the next stages).  Any other error stops the whole
+pipeline: the context passed to the stages is cancelled, the source is no longer read and Run returns the error
+(errors from several workers are joined).  The order of items is kept only when all stages have a single worker.
+*/
+
+// ErrSkipItem - returned by a pipeline stage to drop the item without failing the pipeline
+var ErrSkipItem = errors.New("skip item")
+
+type pipeStage[T any] struct {
+	workers int
+	fn      func(ctx context.Context, item T) (T, error)
+}
+
+type Pipeline[T any] struct {
+	stages []pipeStage[T]
+}
+
+// NewPipeline - create an empty pipeline
+func NewPipeline[T any]() *Pipeline[T] {
+	return &Pipeline[T]{}
+}
+
+// Stage - add a stage run by workers goroutines (at least 1), returns the pipeline for chaining
+func (p *Pipeline[T]) Stage(workers int, fn func(ctx context.Context, item T) (T, error)) *Pipeline[T] {
+	p.stages = append(p.stages, pipeStage[T]{workers: max(workers, 1), fn: fn})
+	return p
+}
+
+// Run - feed the items of src through the stages and wait until all are done.  returns the stage errors, or the
+// context error if ctx was cancelled.
+func (p *Pipeline[T]) Run(ctx context.Context, src iter.Seq[T]) error {
+	g, gctx := NewGroup(ctx)
+	feed := make(chan T)
+	g.Go(func() error {
+		defer close(feed)
+		for item := range src {
+			select {
+			case feed <- item:
+			case <-gctx.Done():
+				return nil
+			}
+		}
+		return nil
+	})
+	in := feed
+	for i, st := range p.stages {
+		last := i == len(p.stages)-1
+		stageIn := in
+		var out chan T
+		if !last {
+			out = make(chan T, st.workers)
+		}
+		var wg sync.WaitGroup
+		wg.Add(st.workers)
+		for w := 0; w < st.workers; w++ {
+			g.Go(func() error {
+				defer wg.Done()
+				for item := range stageIn {
+					if gctx.Err() != nil {
+						continue // drain so the previous stage is not blocked
+					}
+					res, err := st.fn(gctx, item)
+					if errors.Is(err, ErrSkipItem) {
+						continue
+					}
+					if err != nil {
+						return err
+					}
+					if !last {
+						select {
+						case out <- res:
+						case <-gctx.Done():
+						}
+					}
+				}
+				return nil
+			})
+		}
+		if !last {
+			// close the stage output once all its workers are done
+			go func() {
+				wg.Wait()
+				close(out)
+			}()
+			in = out
+		}
+	}
+	if len(p.stages) == 0 {
+		g.Go(func() error {
+			for range in {
+			}
+			return nil
+		})
+	}
+	err := g.Wait()
+	if err == nil {
+		err = ctx.Err()
+	}
+	return err
+}
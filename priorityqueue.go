@@ -0,0 +1,172 @@
+package razutils
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+/*
+PriorityQueue - a thread safe priority queue, Pop returns the item with the highest priority.
+By default a larger priority number is a higher priority; a different order can be given as a comparator
+to NewPriorityQueue (e.g. func(a, b int) bool { return a < b } to make 1 come before 2).
+Items of equal priority are returned in FIFO order.  To prevent starvation of low priority items, SetAging makes
+waiting items gain priority over time.
+*/
+
+type pqItem[T comparable] struct {
+	value    T
+	priority int
+	boost    int       // priority gained by aging
+	seq      uint64    // push order, for FIFO among equal priorities
+	at       time.Time // push time, for aging
+	index    int
+}
+
+// pqHeap - the container/heap implementation under the queue
+type pqHeap[T comparable] struct {
+	items  []*pqItem[T]
+	higher func(a, b int) bool
+}
+
+func (h *pqHeap[T]) Len() int { return len(h.items) }
+func (h *pqHeap[T]) Less(i, j int) bool {
+	pi, pj := h.items[i].priority+h.items[i].boost, h.items[j].priority+h.items[j].boost
+	if pi == pj {
+		return h.items[i].seq < h.items[j].seq
+	}
+	return h.higher(pi, pj)
+}
+func (h *pqHeap[T]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index = i
+	h.items[j].index = j
+}
+func (h *pqHeap[T]) Push(x any) {
+	it := x.(*pqItem[T])
+	it.index = len(h.items)
+	h.items = append(h.items, it)
+}
+func (h *pqHeap[T]) Pop() any {
+	n := len(h.items)
+	it := h.items[n-1]
+	h.items[n-1] = nil
+	h.items = h.items[:n-1]
+	return it
+}
+
+type PriorityQueue[T comparable] struct {
+	h          pqHeap[T]
+	seq        uint64
+	agingStep  int // priority gained per agingEvery of waiting (signed by the queue order), 0 is no aging
+	agingEvery time.Duration
+	lastAging  time.Time
+	mu         sync.Mutex
+}
+
+// NewPriorityQueue - create an empty priority queue. higher reports if priority a comes before priority b, nil means
+// the larger number is the higher priority.
+func NewPriorityQueue[T comparable](higher func(a, b int) bool) *PriorityQueue[T] {
+	if higher == nil {
+		higher = func(a, b int) bool { return a > b }
+	}
+	return &PriorityQueue[T]{h: pqHeap[T]{higher: higher}}
+}
+
+// SetAging - make waiting items gain step priority levels for every period they wait, so low priority items
+// eventually run even under a constant flow of higher priority ones.  a step of 0 turns aging off.
+func (q *PriorityQueue[T]) SetAging(every time.Duration, step int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if every <= 0 || step <= 0 {
+		q.agingStep, q.agingEvery = 0, 0
+		for _, it := range q.h.items {
+			it.boost = 0
+		}
+		heap.Init(&q.h)
+		return
+	}
+	if !q.h.higher(1, 0) {
+		step = -step // smaller numbers are higher priorities
+	}
+	q.agingStep, q.agingEvery = step, every
+	q.ageLocked(true)
+}
+
+// ageLocked - recompute the aging boosts, at most once per aging period unless forced (must be called under lock)
+func (q *PriorityQueue[T]) ageLocked(force bool) {
+	if q.agingStep == 0 {
+		return
+	}
+	now := time.Now()
+	if !force && now.Sub(q.lastAging) < q.agingEvery {
+		return
+	}
+	q.lastAging = now
+	for _, it := range q.h.items {
+		it.boost = int(now.Sub(it.at)/q.agingEvery) * q.agingStep
+	}
+	heap.Init(&q.h)
+}
+
+// Push - add an item with the given priority
+func (q *PriorityQueue[T]) Push(item T, priority int) {
+	q.mu.Lock()
+	q.seq++
+	heap.Push(&q.h, &pqItem[T]{value: item, priority: priority, seq: q.seq, at: time.Now()})
+	q.mu.Unlock()
+}
+
+// Pop - remove and return the highest priority item. Error is returned if the queue is empty
+func (q *PriorityQueue[T]) Pop() (T, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.h.Len() == 0 {
+		var zero T
+		return zero, ErrQueueEmpty
+	}
+	q.ageLocked(false)
+	return heap.Pop(&q.h).(*pqItem[T]).value, nil
+}
+
+// Top - return the highest priority item and its priority without removing it. Error is returned if the queue is empty.
+// the returned priority is the one it was pushed (or updated) with, without the aging boost.
+func (q *PriorityQueue[T]) Top() (T, int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.h.Len() == 0 {
+		var zero T
+		return zero, 0, ErrQueueEmpty
+	}
+	q.ageLocked(false)
+	return q.h.items[0].value, q.h.items[0].priority, nil
+}
+
+// UpdatePriority - change the priority of an item already in the queue. returns false if the item is not in it.
+// if the item was pushed more than once only one of the copies is updated.  the item keeps its place among items of
+// the same priority and its aging.
+func (q *PriorityQueue[T]) UpdatePriority(item T, priority int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, it := range q.h.items {
+		if it.value == item {
+			it.priority = priority
+			heap.Fix(&q.h, it.index)
+			return true
+		}
+	}
+	return false
+}
+
+// Len - return the queue length
+func (q *PriorityQueue[T]) Len() int {
+	q.mu.Lock()
+	res := q.h.Len()
+	q.mu.Unlock()
+	return res
+}
+
+// IsEmpty - check if the queue is empty
+func (q *PriorityQueue[T]) IsEmpty() bool {
+	return q.Len() == 0
+}
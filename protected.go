@@ -0,0 +1,64 @@
+package razutils
+
+import (
+	"sync"
+)
+
+/*
+Protected - a value guarded by a RWMutex, instead of writing the lock/unlock boilerplate around every access:
+
+	var cfg Protected[Config]
+	cfg.Set(loadConfig())
+	cfg.Update(func(c *Config) { c.Workers++ })
+	workers := cfg.Get().Workers
+
+The zero value holds the zero T and is ready to use.  Get returns a copy, so for types holding maps, slices or
+pointers the referenced data is shared and should be accessed within View/Update only.
+*/
+
+type Protected[T any] struct {
+	v  T
+	mu sync.RWMutex
+}
+
+// NewProtected - create a protected value holding v
+func NewProtected[T any](v T) *Protected[T] {
+	return &Protected[T]{v: v}
+}
+
+// Get - return a copy of the value
+func (p *Protected[T]) Get() T {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.v
+}
+
+// Set - replace the value
+func (p *Protected[T]) Set(v T) {
+	p.mu.Lock()
+	p.v = v
+	p.mu.Unlock()
+}
+
+// Swap - replace the value returning the old one
+func (p *Protected[T]) Swap(v T) T {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	old := p.v
+	p.v = v
+	return old
+}
+
+// Update - change the value in place under the write lock. fn must not call the methods of p
+func (p *Protected[T]) Update(fn func(v *T)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fn(&p.v)
+}
+
+// View - read the value in place under the read lock (no copy). fn must not change it or call the methods of p
+func (p *Protected[T]) View(fn func(v *T)) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	fn(&p.v)
+}
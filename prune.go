@@ -0,0 +1,53 @@
+package razutils
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// PruneEmptyDirs - delete the empty directories under root, bottom-up, so a directory holding only empty directories
+// is removed as well.  root itself is never removed.  With dryRun nothing is deleted and the directories that would
+// be removed are returned.
+func PruneEmptyDirs(root string, dryRun bool) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(LongPath(root), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	removed := []string{}
+	gone := make(map[string]bool)
+	// walk order is parent before children, so going backwards handles children first
+	for i := len(dirs) - 1; i > 0; i-- {
+		entries, err := os.ReadDir(dirs[i])
+		if err != nil {
+			return removed, err
+		}
+		empty := true
+		for _, e := range entries {
+			if !gone[filepath.Join(dirs[i], e.Name())] {
+				empty = false
+				break
+			}
+		}
+		if !empty {
+			continue
+		}
+		if !dryRun {
+			if err := os.Remove(dirs[i]); err != nil {
+				return removed, err
+			}
+		}
+		gone[dirs[i]] = true
+		removed = append(removed, dirs[i])
+	}
+	return removed, nil
+}
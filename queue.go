@@ -3,36 +3,168 @@ package razutils
 import (
 	"errors"
 	"golang.org/x/exp/slices"
+	"iter"
 	"sync"
+	"time"
 )
 
 /*
 a Simple thread safe FIFO Queue implementation
 All access to the queue information is done under a Mutex locking, so its thread safe to be called from multiple
 goroutines.
-For some functions to work the items in the queue must be comparable.  However, with using the unique feature any
-type can be added to the queue.
+TypedQueue[T] holds items of a single comparable type, so Pop/Top return typed values with no assertions needed.
+Queue is the original untyped queue, it is the same implementation holding interface{} items.  For some functions to
+work the items in such a queue must be comparable.  However, with using the unique feature any type can be added.
 
 Not copyright and no warranty is made - use at your own discretion
 */
 
-type Queue struct {
-	data        []interface{} // it must provide the comparison to work
+// ErrQueueEmpty - returned when trying to get an item from an empty queue
+var ErrQueueEmpty = errors.New("queue empty")
+
+// qEntry - an item in the queue with the time it was pushed (only set when wait times are tracked) and the time it
+// expires (only for items pushed with PushTTL)
+type qEntry[T comparable] struct {
+	v   T
+	at  time.Time
+	exp time.Time
+}
+
+// expired - check if the entry has a ttl that passed. now is fetched only when needed and kept for the next calls.
+func (e *qEntry[T]) expired(now *time.Time) bool {
+	if e.exp.IsZero() {
+		return false
+	}
+	if now.IsZero() {
+		*now = time.Now()
+	}
+	return !now.Before(e.exp)
+}
+
+type TypedQueue[T comparable] struct {
+	data        []qEntry[T] // data[head:] are the items in the queue, the slots before head are cleared
+	head        int
 	length      int
 	totalPushed int
+	totalPopped int
+	expired     int
+	highWater   int
+	waits       *waitStats   // nil unless TrackWaitTimes was turned on
+	inflight    []*qEntry[T] // items taken by Chan and not received yet, they still count as in the queue
 	mu          sync.Mutex
+	cond        *sync.Cond // created on first blocking wait, signalled on push
 }
 
+// Queue - the untyped queue, items must provide the comparison for InQueue and the unique pushes to work
+type Queue = TypedQueue[interface{}]
+
 // MakeQueue - create a new Queue with a starting capacity (it's a slice based, so its just allocating initial capacity).
 func MakeQueue(initSize int) Queue {
+	return MakeTypedQueue[interface{}](initSize)
+}
+
+// MakeTypedQueue - create a new TypedQueue with a starting capacity, e.g. MakeTypedQueue[string](100)
+func MakeTypedQueue[T comparable](initSize int) TypedQueue[T] {
 	if initSize <= 0 {
-		return Queue{}
+		return TypedQueue[T]{}
+	}
+	return TypedQueue[T]{data: make([]qEntry[T], 0, initSize), length: 0, totalPushed: 0}
+}
+
+// appendLocked - add items at the tail and update the counters (must be called under lock)
+func (q *TypedQueue[T]) appendLocked(items ...T) {
+	var now time.Time
+	if q.waits != nil {
+		now = time.Now()
+	}
+	for _, it := range items {
+		q.data = append(q.data, qEntry[T]{v: it, at: now})
+	}
+	q.length += len(items)
+	q.totalPushed += len(items)
+	q.highWater = max(q.highWater, q.length)
+	q.wake()
+}
+
+// takeLocked - remove up to n items from the head and update the counters, expired items met on the way are dropped
+// so fewer than n items may be returned (must be called under lock)
+func (q *TypedQueue[T]) takeLocked(n int) []T {
+	items := make([]T, 0, min(n, q.length))
+	var now time.Time
+	if q.waits != nil {
+		now = time.Now()
+	}
+	i := 0
+	for ; i < q.length && len(items) < n; i++ {
+		e := &q.data[q.head+i]
+		if e.expired(&now) {
+			q.expired++
+			continue
+		}
+		items = append(items, e.v)
+		if q.waits != nil && !e.at.IsZero() {
+			q.waits.add(now.Sub(e.at))
+		}
+	}
+	q.advanceLocked(i)
+	q.totalPopped += len(items)
+	return items
+}
+
+// compactMin - the backing array is compacted only once at least that many slots before the head are unused
+const compactMin = 64
+
+// advanceLocked - drop n items from the head. the slots are cleared so popped items can be garbage collected, and
+// once more than half of the backing array is unused the items are moved to a new right sized array, so a queue that
+// once held many items does not keep all that memory (must be called under lock)
+func (q *TypedQueue[T]) advanceLocked(n int) {
+	clear(q.data[q.head : q.head+n])
+	q.head += n
+	q.length -= n
+	switch {
+	case q.length == 0 && cap(q.data) > compactMin:
+		q.data, q.head = nil, 0
+	case q.length == 0:
+		q.data, q.head = q.data[:0], 0
+	case q.head >= compactMin && q.head >= len(q.data)/2:
+		nd := make([]qEntry[T], q.length, 2*q.length)
+		copy(nd, q.data[q.head:])
+		q.data, q.head = nd, 0
+	}
+}
+
+// lenLocked - the number of items, including those waiting in a Chan to be received (must be called under lock)
+func (q *TypedQueue[T]) lenLocked() int {
+	return q.length + len(q.inflight)
+}
+
+// liveLocked - call fn for each item not expired, oldest first (the items waiting in a Chan first), until fn returns
+// false (must be called under lock)
+func (q *TypedQueue[T]) liveLocked(fn func(e *qEntry[T]) bool) {
+	var now time.Time
+	for _, e := range q.inflight {
+		if !e.expired(&now) && !fn(e) {
+			return
+		}
+	}
+	for i := range q.data[q.head:] {
+		if e := &q.data[q.head+i]; !e.expired(&now) && !fn(e) {
+			return
+		}
+	}
+}
+
+// dropExpiredHeadLocked - remove the expired items at the head, so the head is a live item (must be called under lock)
+func (q *TypedQueue[T]) dropExpiredHeadLocked() {
+	var now time.Time
+	for q.length > 0 && q.data[q.head].expired(&now) {
+		q.advanceLocked(1)
+		q.expired++
 	}
-	return Queue{data: make([]interface{}, 0, initSize), length: 0, totalPushed: 0}
 }
 
 // TotalIn - return the total number of items added to the queue
-func (q *Queue) TotalIn() int {
+func (q *TypedQueue[T]) TotalIn() int {
 	q.mu.Lock()
 	x := q.totalPushed
 	q.mu.Unlock()
@@ -40,61 +172,56 @@ func (q *Queue) TotalIn() int {
 }
 
 // Top - return the top (i.e. the oldest) item without removing it. Error is returned if the queue is empty
-func (q *Queue) Top() (interface{}, error) {
+func (q *TypedQueue[T]) Top() (T, error) {
 	//log.Println("Q pop: before", q.data)
 	q.mu.Lock()
+	q.dropExpiredHeadLocked()
 	if q.length == 0 {
 		q.mu.Unlock()
-		return nil, errors.New("queue empty")
+		var zero T
+		return zero, ErrQueueEmpty
 	}
-	item := q.data[0]
+	item := q.data[q.head].v
 	q.mu.Unlock()
 	return item, nil
 
 }
 
 // Pop - return the top (i.e. the oldest) item while removing it. Error is returned if the queue is empty
-func (q *Queue) Pop() (interface{}, error) {
+func (q *TypedQueue[T]) Pop() (T, error) {
 	//log.Println("Q pop: before", q.data)
 	q.mu.Lock()
-	if q.length == 0 {
-		q.mu.Unlock()
-		return nil, errors.New("queue empty")
-	}
-	item := q.data[0]
-	q.data = q.data[1:]
-	q.length -= 1
+	items := q.takeLocked(1)
 	q.mu.Unlock()
-	return item, nil
+	if len(items) == 0 {
+		var zero T
+		return zero, ErrQueueEmpty
+	}
+	return items[0], nil
 }
 
 // Push - Push an item into the queue
-func (q *Queue) Push(dt interface{}) {
+func (q *TypedQueue[T]) Push(dt T) {
 	q.mu.Lock()
-	q.data = append(q.data, dt)
-	q.length += 1
-	q.totalPushed += 1
+	q.appendLocked(dt)
 	q.mu.Unlock()
 }
 
 // PushUnique - Push an item into the queue only if It's not already in it
-func (q *Queue) PushUnique(dt interface{}) {
-	if !q.InQueue(dt) {
-		q.mu.Lock()
-		q.data = append(q.data, dt)
-		q.length += 1
-		q.totalPushed += 1
-		q.mu.Unlock()
+func (q *TypedQueue[T]) PushUnique(dt T) {
+	q.mu.Lock()
+	if !q.inQueueLocked(dt) {
+		q.appendLocked(dt)
 	}
+	q.mu.Unlock()
 }
 
 // PushMany - Push many items into the queue. If unique is true only new items will be pushed
-func (q *Queue) PushMany(dt []interface{}, unique bool) {
+func (q *TypedQueue[T]) PushMany(dt []T, unique bool) {
 	if len(dt) > 0 {
 		if !unique {
 			q.mu.Lock()
-			q.data = append(q.data, dt...)
-			q.length += len(dt)
+			q.appendLocked(dt...)
 			q.mu.Unlock()
 		} else {
 			for _, d := range dt {
@@ -105,28 +232,161 @@ func (q *Queue) PushMany(dt []interface{}, unique bool) {
 }
 
 // Len - return the queue length
-func (q *Queue) Len() int {
+func (q *TypedQueue[T]) Len() int {
 	q.mu.Lock()
-	res := q.length
+	res := q.lenLocked()
 	q.mu.Unlock()
 	return res
 }
 
 // IsEmpty - check if a queue is empty
-func (q *Queue) IsEmpty() bool {
+func (q *TypedQueue[T]) IsEmpty() bool {
 	q.mu.Lock()
-	res := q.length == 0
+	res := q.lenLocked() == 0
 	q.mu.Unlock()
 	return res
 }
 
 // InQueue - check if an item is in the queue
-func (q *Queue) InQueue(s interface{}) bool {
+func (q *TypedQueue[T]) InQueue(s T) bool {
 	q.mu.Lock()
-	if q.Len() == 0 {
-		return false
-	}
-	res := slices.IndexFunc(q.data, func(c interface{}) bool { return c == s }) != -1
+	res := q.inQueueLocked(s)
 	q.mu.Unlock()
 	return res
 }
+
+// inQueueLocked - InQueue for callers already holding the lock (the mutex is not reentrant, so the locking methods
+// such as Len must never be called with it held).  Expired items are not in the queue, so PushUnique adds the item
+// again.
+func (q *TypedQueue[T]) inQueueLocked(s T) bool {
+	found := false
+	q.liveLocked(func(e *qEntry[T]) bool {
+		found = e.v == s
+		return !found
+	})
+	return found
+}
+
+// PopN - remove and return up to n of the oldest items under a single lock. an empty slice is returned if the
+// queue is empty.
+func (q *TypedQueue[T]) PopN(n int) []T {
+	q.mu.Lock()
+	if n < 0 {
+		n = 0
+	}
+	items := q.takeLocked(n)
+	q.mu.Unlock()
+	return items
+}
+
+// PopAll - remove and return all the items in the queue
+func (q *TypedQueue[T]) PopAll() []T {
+	q.mu.Lock()
+	items := q.takeLocked(q.length)
+	q.mu.Unlock()
+	return items
+}
+
+// DrainTo - pop items until the queue is empty calling fn for each of them (outside the lock, so fn may push more
+// items, which are drained as well).  returns the number of items drained.
+func (q *TypedQueue[T]) DrainTo(fn func(T)) int {
+	cnt := 0
+	for {
+		items := q.PopAll()
+		if len(items) == 0 {
+			return cnt
+		}
+		for _, item := range items {
+			fn(item)
+		}
+		cnt += len(items)
+	}
+}
+
+// Snapshot - return a copy of the queue content, oldest first, without changing the queue.  Expired items are left
+// out.
+func (q *TypedQueue[T]) Snapshot() []T {
+	q.mu.Lock()
+	items := make([]T, 0, q.lenLocked())
+	q.liveLocked(func(e *qEntry[T]) bool {
+		items = append(items, e.v)
+		return true
+	})
+	q.mu.Unlock()
+	return items
+}
+
+// PeekAt - return the item at position i (0 is the oldest) without removing it, expired items are skipped. Error is
+// returned if i is out of range
+func (q *TypedQueue[T]) PeekAt(i int) (T, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var item T
+	found := false
+	q.liveLocked(func(e *qEntry[T]) bool {
+		if i == 0 {
+			item, found = e.v, true
+		}
+		i--
+		return i >= 0
+	})
+	if !found {
+		return item, errors.New("queue index out of range")
+	}
+	return item, nil
+}
+
+// Remove - delete the first occurrence of an item from the queue. returns false if it was not in the queue
+func (q *TypedQueue[T]) Remove(item T) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i := slices.IndexFunc(q.data[q.head:], func(c qEntry[T]) bool { return c.v == item })
+	if i == -1 {
+		return false
+	}
+	q.data = slices.Delete(q.data, q.head+i, q.head+i+1)
+	q.length -= 1
+	return true
+}
+
+// Filter - keep in the queue only the items for which keep returns true, in their order. returns the number of
+// items removed.  keep is called under the queue lock so it must not call the queue methods.
+func (q *TypedQueue[T]) Filter(keep func(T) bool) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.filterLocked(func(e *qEntry[T]) bool { return keep(e.v) })
+}
+
+// filterLocked - keep only the entries for which keep returns true, returning the number removed (must be called
+// under lock)
+func (q *TypedQueue[T]) filterLocked(keep func(e *qEntry[T]) bool) int {
+	live := q.data[q.head:]
+	kept := live[:0]
+	for i := range live {
+		if keep(&live[i]) {
+			kept = append(kept, live[i])
+		}
+	}
+	removed := len(live) - len(kept)
+	clear(live[len(kept):]) // do not keep references to the removed items
+	q.data = q.data[:q.head+len(kept)]
+	q.length -= removed
+	return removed
+}
+
+// ForEach - call fn for each item, oldest first, stopping when fn returns false.  The iteration is over a snapshot
+// taken under the lock (without the expired items), so fn may use the queue freely.
+func (q *TypedQueue[T]) ForEach(fn func(T) bool) {
+	for _, item := range q.Snapshot() {
+		if !fn(item) {
+			return
+		}
+	}
+}
+
+// All - return an iterator over a snapshot of the queue items, oldest first: for item := range q.All() {...}
+func (q *TypedQueue[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		q.ForEach(yield)
+	}
+}
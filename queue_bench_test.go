@@ -0,0 +1,47 @@
+package razutils
+
+import (
+	"runtime"
+	"testing"
+)
+
+// BenchmarkQueuePopRetention - the memory a queue keeps after a burst of items was popped down to a few, reported as
+// retained-B/op (it should stay around the size of the few remaining items, not of the burst)
+func BenchmarkQueuePopRetention(b *testing.B) {
+	const burst, left = 100_000, 10
+	var retained uint64
+	var ms runtime.MemStats
+	for i := 0; i < b.N; i++ {
+		runtime.GC()
+		runtime.ReadMemStats(&ms)
+		before := ms.HeapAlloc
+		q := MakeTypedQueue[*[64]byte](0)
+		for j := 0; j < burst; j++ {
+			q.Push(new([64]byte))
+		}
+		for j := 0; j < burst-left; j++ {
+			q.Pop()
+		}
+		runtime.GC()
+		runtime.ReadMemStats(&ms)
+		if ms.HeapAlloc > before {
+			retained += ms.HeapAlloc - before
+		}
+		runtime.KeepAlive(&q)
+	}
+	b.ReportMetric(float64(retained)/float64(b.N), "retained-B/op")
+}
+
+// BenchmarkQueuePushPop - a steady flow through the queue, pops keep the backing array from growing
+func BenchmarkQueuePushPop(b *testing.B) {
+	q := MakeTypedQueue[int](0)
+	for i := 0; i < 1000; i++ {
+		q.Push(i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.Push(i)
+		q.Pop()
+	}
+}
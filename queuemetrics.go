@@ -0,0 +1,103 @@
+package razutils
+
+import (
+	"slices"
+	"time"
+)
+
+// waitSamples - number of recent wait times kept for the percentiles
+const waitSamples = 1024
+
+// QueueMetrics - counters and wait time statistics of a queue, as returned by Metrics.
+// wait times are only available after TrackWaitTimes(true), and cover items pushed since then.
+type QueueMetrics struct {
+	Len       int // current length
+	TotalIn   int // items pushed
+	TotalOut  int // items popped
+	Expired   int // items dropped because their ttl passed
+	HighWater int // the maximal length the queue reached
+	Waits     int // number of wait times measured
+	WaitAvg   time.Duration
+	WaitMax   time.Duration
+	WaitP50   time.Duration // percentiles are over the most recent waits
+	WaitP95   time.Duration
+	WaitP99   time.Duration
+}
+
+// waitStats - wait time statistics: totals plus a window of recent samples
+type waitStats struct {
+	count  int
+	total  time.Duration
+	max    time.Duration
+	recent []time.Duration
+	next   int
+}
+
+func (w *waitStats) add(d time.Duration) {
+	w.count++
+	w.total += d
+	w.max = max(w.max, d)
+	if len(w.recent) < waitSamples {
+		w.recent = append(w.recent, d)
+	} else {
+		w.recent[w.next] = d
+		w.next = (w.next + 1) % waitSamples
+	}
+}
+
+// TrackWaitTimes - turn on (or off) the recording of the time items wait in the queue.  This costs a time.Now() per
+// push and pop so it is off by default.
+func (q *TypedQueue[T]) TrackWaitTimes(on bool) {
+	q.mu.Lock()
+	if !on {
+		q.waits = nil
+	} else if q.waits == nil {
+		q.waits = &waitStats{}
+	}
+	q.mu.Unlock()
+}
+
+// TotalOut - return the total number of items removed from the queue
+func (q *TypedQueue[T]) TotalOut() int {
+	q.mu.Lock()
+	x := q.totalPopped
+	q.mu.Unlock()
+	return x
+}
+
+// Metrics - return the queue counters and wait time statistics
+func (q *TypedQueue[T]) Metrics() QueueMetrics {
+	q.mu.Lock()
+	m := QueueMetrics{Len: q.lenLocked(), TotalIn: q.totalPushed, TotalOut: q.totalPopped, Expired: q.expired, HighWater: q.highWater}
+	var recent []time.Duration
+	if q.waits != nil && q.waits.count > 0 {
+		m.Waits = q.waits.count
+		m.WaitAvg = q.waits.total / time.Duration(q.waits.count)
+		m.WaitMax = q.waits.max
+		recent = slices.Clone(q.waits.recent)
+	}
+	q.mu.Unlock()
+	if len(recent) > 0 {
+		slices.Sort(recent)
+		pct := func(p int) time.Duration { return recent[(len(recent)-1)*p/100] }
+		m.WaitP50, m.WaitP95, m.WaitP99 = pct(50), pct(95), pct(99)
+	}
+	return m
+}
+
+// RegisterMetrics - report the queue counters through a registry as prefix.len, prefix.in, prefix.out,
+// prefix.expired and prefix.high_water
+func (q *TypedQueue[T]) RegisterMetrics(r *Registry, prefix string) {
+	read := func(f func() int) func() int64 {
+		return func() int64 {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			return int64(f())
+		}
+	}
+	r.Func(prefix+".len", read(func() int { return q.lenLocked() }))
+	r.Func(prefix+".in", read(func() int { return q.totalPushed }))
+	r.Func(prefix+".out", read(func() int { return q.totalPopped }))
+	r.Func(prefix+".expired", read(func() int { return q.expired }))
+	r.Func(prefix+".high_water", read(func() int { return q.highWater }))
+}
@@ -0,0 +1,109 @@
+package razutils
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"os"
+	"time"
+)
+
+/*
+Queue persistence - save the queue content to disk and load it back, so a queue can survive restarts.
+Files are written with WriteFileAtomic so a crash while saving leaves the previous file intact.
+Note that for the untyped Queue, JSON loses the item types (numbers come back as float64 etc.), and gob requires the
+concrete item types to be registered with gob.Register.  Use a TypedQueue when persistence is needed.
+*/
+
+// queueState - what is saved to disk.  Expires holds the expiry of each item (zero for items with no ttl), it is
+// left out when no item has a ttl.
+type queueState[T comparable] struct {
+	Items   []T
+	Expires []time.Time `json:",omitempty"`
+	TotalIn int
+}
+
+// state - the live items, the expired ones are not saved
+func (q *TypedQueue[T]) state() queueState[T] {
+	q.mu.Lock()
+	st := queueState[T]{Items: make([]T, 0, q.lenLocked()), TotalIn: q.totalPushed}
+	exps := make([]time.Time, 0, q.lenLocked())
+	hasTTL := false
+	q.liveLocked(func(e *qEntry[T]) bool {
+		st.Items = append(st.Items, e.v)
+		exps = append(exps, e.exp)
+		hasTTL = hasTTL || !e.exp.IsZero()
+		return true
+	})
+	q.mu.Unlock()
+	if hasTTL {
+		st.Expires = exps
+	}
+	return st
+}
+
+// setState - replace the content with st, items that expired since it was saved are dropped
+func (q *TypedQueue[T]) setState(st queueState[T]) {
+	q.mu.Lock()
+	q.data = nil
+	q.head = 0
+	q.length = 0
+	q.inflight = nil // the items waiting in a Chan are delivered, they are not part of the new content
+	q.appendLocked(st.Items...)
+	if len(st.Expires) == len(st.Items) {
+		for i, exp := range st.Expires {
+			q.data[i].exp = exp
+		}
+		var now time.Time
+		q.filterLocked(func(e *qEntry[T]) bool { return !e.expired(&now) })
+	}
+	q.totalPushed = st.TotalIn
+	q.mu.Unlock()
+}
+
+// SaveJSON - save the queue content into a JSON file
+func (q *TypedQueue[T]) SaveJSON(path string) error {
+	data, err := json.Marshal(q.state())
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data, 0644)
+}
+
+// LoadJSON - replace the queue content with the content of a file written by SaveJSON
+func (q *TypedQueue[T]) LoadJSON(path string) error {
+	data, err := os.ReadFile(LongPath(path))
+	if err != nil {
+		return err
+	}
+	var st queueState[T]
+	if err := json.Unmarshal(data, &st); err != nil {
+		return err
+	}
+	q.setState(st)
+	return nil
+}
+
+// SaveGob - save the queue content into a gob file
+func (q *TypedQueue[T]) SaveGob(path string) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(q.state()); err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, buf.Bytes(), 0644)
+}
+
+// LoadGob - replace the queue content with the content of a file written by SaveGob
+func (q *TypedQueue[T]) LoadGob(path string) error {
+	f, err := os.Open(LongPath(path))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var st queueState[T]
+	if err := gob.NewDecoder(f).Decode(&st); err != nil {
+		return err
+	}
+	q.setState(st)
+	return nil
+}
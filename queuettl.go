@@ -0,0 +1,47 @@
+package razutils
+
+import (
+	"context"
+	"time"
+)
+
+/*
+Expiring items - items pushed with PushTTL carry a time to live.  Once it passes the item is never returned by Pop
+(or the other pop/top functions), it is dropped when reached instead.  Expired items are not in the queue for InQueue
+and PushUnique, and are left out of Snapshot, PeekAt, ForEach and All, but they still count in Len until they are
+reached or purged; Purge removes them all, and StartJanitor runs Purge periodically.
+*/
+
+// PushTTL - push an item that expires after ttl
+func (q *TypedQueue[T]) PushTTL(dt T, ttl time.Duration) {
+	q.mu.Lock()
+	q.appendLocked(dt)
+	q.data[len(q.data)-1].exp = time.Now().Add(ttl)
+	q.mu.Unlock()
+}
+
+// Purge - remove all the expired items from the queue, returning how many were removed
+func (q *TypedQueue[T]) Purge() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var now time.Time
+	removed := q.filterLocked(func(e *qEntry[T]) bool { return !e.expired(&now) })
+	q.expired += removed
+	return removed
+}
+
+// StartJanitor - run Purge every interval in a background goroutine until the context is done
+func (q *TypedQueue[T]) StartJanitor(ctx context.Context, interval time.Duration) {
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				q.Purge()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,64 @@
+package razutils
+
+import (
+	"testing"
+	"time"
+)
+
+// TestQueueExpiredNotPresent - an expired copy must not block PushUnique nor show in the read functions
+func TestQueueExpiredNotPresent(t *testing.T) {
+	q := MakeTypedQueue[string](0)
+	q.PushTTL("a", time.Millisecond)
+	q.Push("b")
+	time.Sleep(5 * time.Millisecond)
+	if q.InQueue("a") {
+		t.Fatal("expired item reported in the queue")
+	}
+	q.PushUnique("a")
+	if got := q.Snapshot(); len(got) != 2 || got[0] != "b" || got[1] != "a" {
+		t.Fatalf("Snapshot = %v", got)
+	}
+	if v, err := q.PeekAt(0); err != nil || v != "b" {
+		t.Fatalf("PeekAt(0) = %v, %v", v, err)
+	}
+	if _, err := q.PeekAt(2); err == nil {
+		t.Fatal("PeekAt(2) should be out of range")
+	}
+	var all []string
+	for v := range q.All() {
+		all = append(all, v)
+	}
+	if len(all) != 2 {
+		t.Fatalf("All = %v", all)
+	}
+	if v, _ := q.Pop(); v != "b" {
+		t.Fatalf("Pop = %v", v)
+	}
+	if v, _ := q.Pop(); v != "a" {
+		t.Fatalf("re-pushed item lost, Pop = %v", v)
+	}
+}
+
+// TestQueuePersistTTL - the expiry of the items is saved, expired items are not
+func TestQueuePersistTTL(t *testing.T) {
+	q := MakeTypedQueue[string](0)
+	q.PushTTL("gone", time.Millisecond)
+	q.PushTTL("short", 50*time.Millisecond)
+	q.Push("keep")
+	time.Sleep(5 * time.Millisecond)
+	path := t.TempDir() + "/q.json"
+	if err := q.SaveJSON(path); err != nil {
+		t.Fatal(err)
+	}
+	q2 := MakeTypedQueue[string](0)
+	if err := q2.LoadJSON(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := q2.Snapshot(); len(got) != 2 || got[0] != "short" || got[1] != "keep" {
+		t.Fatalf("loaded %v", got)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if got := q2.Snapshot(); len(got) != 1 || got[0] != "keep" {
+		t.Fatalf("the ttl was not restored: %v", got)
+	}
+}
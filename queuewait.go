@@ -0,0 +1,150 @@
+package razutils
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// wake - wake up the goroutines waiting for items (must be called under lock)
+func (q *TypedQueue[T]) wake() {
+	if q.cond != nil {
+		q.cond.Broadcast()
+	}
+}
+
+// PopWait - remove and return the oldest item, blocking until one is pushed if the queue is empty.  The wait uses a
+// condition variable (no polling) and ends with the context error if the context is done first.
+func (q *TypedQueue[T]) PopWait(ctx context.Context) (T, error) {
+	var item T
+	err := q.waitFor(ctx, func() bool {
+		items := q.takeLocked(1)
+		if len(items) == 0 {
+			return false
+		}
+		item = items[0]
+		return true
+	})
+	return item, err
+}
+
+// waitFor - call take under the lock until it returns true, waiting for pushes in between.  The context error is
+// returned if the context is done first.
+func (q *TypedQueue[T]) waitFor(ctx context.Context, take func() bool) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.cond == nil {
+		q.cond = sync.NewCond(&q.mu)
+	}
+	if take() {
+		return nil
+	}
+	// a cond can not select on a context, so wake all the waiters when it is done
+	stop := context.AfterFunc(ctx, func() {
+		q.mu.Lock()
+		q.cond.Broadcast()
+		q.mu.Unlock()
+	})
+	defer stop()
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		q.cond.Wait()
+		if take() {
+			return nil
+		}
+	}
+}
+
+// PopWaitTimeout - like PopWait, but gives up with context.DeadlineExceeded after timeout
+func (q *TypedQueue[T]) PopWaitTimeout(timeout time.Duration) (T, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return q.PopWait(ctx)
+}
+
+// takeInflightLocked - move the oldest live item to the items waiting in a Chan, it is not counted as popped yet (must
+// be called under lock)
+func (q *TypedQueue[T]) takeInflightLocked() (*qEntry[T], bool) {
+	q.dropExpiredHeadLocked()
+	if q.length == 0 {
+		return nil, false
+	}
+	e := q.data[q.head]
+	q.advanceLocked(1)
+	q.inflight = append(q.inflight, &e)
+	return &e, true
+}
+
+// removeInflightLocked - remove e from the items waiting in a Chan, false if it is not there (the queue content was
+// replaced by a load) (must be called under lock)
+func (q *TypedQueue[T]) removeInflightLocked(e *qEntry[T]) bool {
+	i := slices.Index(q.inflight, e)
+	if i == -1 {
+		return false
+	}
+	q.inflight = slices.Delete(q.inflight, i, i+1)
+	return true
+}
+
+// delivered - a Chan item was received, it is now popped
+func (q *TypedQueue[T]) delivered(e *qEntry[T]) {
+	q.mu.Lock()
+	if q.removeInflightLocked(e) {
+		q.totalPopped++
+		if q.waits != nil && !e.at.IsZero() {
+			q.waits.add(time.Since(e.at))
+		}
+	}
+	q.mu.Unlock()
+}
+
+// putBack - put a Chan item that was not received back at the head of the queue, with its push time and expiry
+func (q *TypedQueue[T]) putBack(e *qEntry[T]) {
+	q.mu.Lock()
+	if q.removeInflightLocked(e) {
+		if q.head > 0 {
+			q.head--
+			q.data[q.head] = *e
+		} else {
+			q.data = append([]qEntry[T]{*e}, q.data...)
+		}
+		q.length++
+		q.wake()
+	}
+	q.mu.Unlock()
+}
+
+// Chan - return a channel delivering the queue items as they are pushed, so consumers can select on the queue
+// together with other channels.  Each item is delivered once, so several consumers (and Chan calls) share the items
+// between them.  The next item is set aside for the channel until it is received: meanwhile it still counts in Len
+// and shows in Snapshot and in the saved files, but Pop and the other channels get the items after it.  The channel
+// is closed when the context is done, and an item not received by then is put back at the head of the queue with its
+// ttl.  A consumer that stops reading should cancel the context, or the item set aside stays there.
+func (q *TypedQueue[T]) Chan(ctx context.Context) <-chan T {
+	ch := make(chan T)
+	go func() {
+		defer close(ch)
+		for {
+			var e *qEntry[T]
+			err := q.waitFor(ctx, func() bool {
+				var ok bool
+				e, ok = q.takeInflightLocked()
+				return ok
+			})
+			if err != nil {
+				return
+			}
+			select {
+			case ch <- e.v:
+				q.delivered(e)
+			case <-ctx.Done():
+				q.putBack(e)
+				return
+			}
+		}
+	}()
+	return ch
+}
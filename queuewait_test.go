@@ -0,0 +1,55 @@
+package razutils
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestQueueChanItemCounted - the item set aside for a Chan still counts and is saved until it is received, and
+// keeps its ttl when put back
+func TestQueueChanItemCounted(t *testing.T) {
+	q := MakeTypedQueue[string](0)
+	q.PushTTL("a", time.Hour)
+	q.Push("b")
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := q.Chan(ctx)
+	time.Sleep(20 * time.Millisecond) // the goroutine sets "a" aside, nobody receives it
+	if q.Len() != 2 {
+		t.Fatalf("Len = %d", q.Len())
+	}
+	if got := q.Snapshot(); len(got) != 2 || got[0] != "a" {
+		t.Fatalf("Snapshot = %v", got)
+	}
+	if st := q.state(); len(st.Items) != 2 || st.Expires[0].IsZero() {
+		t.Fatalf("saved state %+v", st)
+	}
+	if q.TotalOut() != 0 {
+		t.Fatalf("TotalOut = %d before receiving", q.TotalOut())
+	}
+	if v := <-ch; v != "a" {
+		t.Fatalf("received %v", v)
+	}
+	time.Sleep(20 * time.Millisecond) // the delivery is recorded, and "b" is set aside
+	if q.TotalOut() != 1 {
+		t.Fatalf("TotalOut = %d", q.TotalOut())
+	}
+	cancel()
+	for range ch {
+	}
+	if got := q.Snapshot(); len(got) != 1 || got[0] != "b" || q.Len() != 1 {
+		t.Fatalf("after cancel %v", got)
+	}
+	q2 := MakeTypedQueue[string](0)
+	q2.PushTTL("x", 30*time.Millisecond)
+	ctx, cancel = context.WithCancel(context.Background())
+	ch = q2.Chan(ctx)
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	for range ch {
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, err := q2.Pop(); err != ErrQueueEmpty {
+		t.Fatal("the ttl was lost when the item was put back")
+	}
+}
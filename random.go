@@ -0,0 +1,74 @@
+package razutils
+
+import (
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+Random helpers used by the package (RandFileName, Shuffle, WeightedChooser, Retry jitter...) and available to the
+programs.  By default they use the math/rand/v2 runtime source, which is seeded randomly, safe for concurrent use and
+has no lock contention.  SeedRand switches them to a single seeded generator (under a lock) so runs can be reproduced,
+e.g. in tests.
+*/
+
+var (
+	seeded   atomic.Pointer[rand.Rand] // nil unless SeedRand was called, loaded without the lock by the default path
+	seededMu sync.Mutex                // held while using the seeded generator, it is not safe for concurrent use
+)
+
+// SeedRand - make the package random helpers deterministic, using a PCG generator seeded with seed
+func SeedRand(seed uint64) {
+	seeded.Store(rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)))
+}
+
+// UnseedRand - go back to the default random source
+func UnseedRand() {
+	seeded.Store(nil)
+}
+
+// RandInt64n - return a random number in [0,n). panics if n <= 0
+func RandInt64n(n int64) int64 {
+	r := seeded.Load()
+	if r == nil {
+		return rand.Int64N(n)
+	}
+	seededMu.Lock()
+	defer seededMu.Unlock()
+	return r.Int64N(n)
+}
+
+// RandIntn - return a random number in [0,n). panics if n <= 0
+func RandIntn(n int) int {
+	return int(RandInt64n(int64(n)))
+}
+
+// RandFloat64 - return a random number in [0.0,1.0)
+func RandFloat64() float64 {
+	r := seeded.Load()
+	if r == nil {
+		return rand.Float64()
+	}
+	seededMu.Lock()
+	defer seededMu.Unlock()
+	return r.Float64()
+}
+
+// RandDuration - return a random duration in [minD,maxD]. if maxD <= minD minD is returned
+func RandDuration(minD, maxD time.Duration) time.Duration {
+	if maxD <= minD {
+		return minD
+	}
+	span := int64(maxD - minD)
+	if span == 1<<63-1 {
+		span-- // so span+1 does not overflow
+	}
+	return minD + time.Duration(RandInt64n(span+1))
+}
+
+// RandChoice - return a random item of s. panics if s is empty
+func RandChoice[T any](s []T) T {
+	return s[RandIntn(len(s))]
+}
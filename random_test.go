@@ -0,0 +1,35 @@
+package razutils
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestSeedRandConcurrent - seeding while other goroutines draw numbers is safe, and a seed repeats its numbers
+func TestSeedRandConcurrent(t *testing.T) {
+	defer UnseedRand()
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				RandIntn(100)
+				RandFloat64()
+			}
+		}()
+	}
+	for i := range 100 {
+		SeedRand(uint64(i))
+		UnseedRand()
+	}
+	wg.Wait()
+	SeedRand(42)
+	a := []int{RandIntn(1000), RandIntn(1000), RandIntn(1000)}
+	SeedRand(42)
+	for i, want := range a {
+		if got := RandIntn(1000); got != want {
+			t.Fatalf("draw %d: got %d after the same seed, want %d", i, got, want)
+		}
+	}
+}
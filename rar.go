@@ -0,0 +1,227 @@
+package razutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/nwaples/rardecode/v2"
+)
+
+/*
+RAR archives, decoded in Go (RAR 1.5 to 5, solid, encrypted and multi volume archives), falling back to the unrar or
+7z programs for what the decoder does not support.
+*/
+
+// rarUnsupportedErrs - decoder errors for archives the external programs may still handle
+var rarUnsupportedErrs = []error{
+	rardecode.ErrUnknownVersion, rardecode.ErrUnknownDecoder, rardecode.ErrUnsupportedDecoder,
+	rardecode.ErrMultipleDecoders, rardecode.ErrUnknownEncryptMethod, rardecode.ErrUnknownFilter,
+	rardecode.ErrDictionaryTooLarge, rardecode.ErrPlatformIntSize,
+}
+
+// RarExtract - extract the rar src into destDir, restoring the directory structure, file modes and modification
+// times.  Multi volume archives are given by their first volume (name.part1.rar or name.rar).  Entries going out of
+// destDir fail the extraction with ErrUnsafePath (see WithLenientPaths) and symlinks are skipped.  WithPassword
+// decrypts encrypted archives, without it they fail with ErrPasswordRequired.  Archives the Go decoder does not
+// support are extracted by unrar or 7z when installed (with no progress, and not with WithMatch or WithFlatten); what
+// the decoder extracted before failing is removed first.  Note that unrar and 7z take the password on their command
+// line, where other local users can see it (ps, /proc) while they run.
+func RarExtract(src string, destDir string, opts ...ArchiveOption) error {
+	cfg := archiveOptions(opts)
+	before, berr := dirNames(destDir)
+	var written []string
+	err := rarExtract(src, destDir, &cfg, &written)
+	if err == nil || len(cfg.match) > 0 || cfg.flatten || !rarUnsupported(err) {
+		return err
+	}
+	x, xerr := rarExternal(cfg.password)
+	if xerr != nil || berr != nil {
+		return err
+	}
+	if cerr := removePartial(destDir, before, written); cerr != nil {
+		return errors.Join(err, cerr)
+	}
+	return x.Extract(context.Background(), src, destDir)
+}
+
+// dirNames - the names in dir, nil if it does not exist
+func dirNames(dir string) (map[string]bool, error) {
+	entries, err := os.ReadDir(LongPath(dir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		names[e.Name()] = true
+	}
+	return names, nil
+}
+
+// removePartial - undo a failed extraction into destDir: the files written are removed, and so is what was not in
+// destDir before (before is nil when destDir did not exist)
+func removePartial(destDir string, before map[string]bool, written []string) error {
+	if before == nil {
+		return os.RemoveAll(LongPath(destDir))
+	}
+	for _, p := range written {
+		if err := os.Remove(LongPath(p)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	entries, err := os.ReadDir(LongPath(destDir))
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if !before[e.Name()] {
+			if err = os.RemoveAll(LongPath(filepath.Join(destDir, e.Name()))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// rarExtract - extract with the Go decoder, the files it writes are added to written
+func rarExtract(src string, destDir string, cfg *archiveConfig, written *[]string) error {
+	ropts := rarOptions(cfg)
+	if cfg.progress != nil {
+		files, err := rardecode.List(src, ropts...)
+		if err != nil {
+			return rarError(err)
+		}
+		for _, f := range files {
+			if cfg.wants(f.Name, f.Mode().IsRegular()) {
+				cfg.progress.total += f.UnPackedSize
+			}
+		}
+	}
+	rc, err := rardecode.OpenReader(src, ropts...)
+	if err != nil {
+		return rarError(err)
+	}
+	defer rc.Close()
+	dt := dirTimes{}
+	for {
+		hdr, err := rc.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return rarError(err)
+		}
+		mode := hdr.Mode()
+		if !cfg.wants(hdr.Name, mode.IsRegular()) {
+			continue
+		}
+		target, err := cfg.target(destDir, hdr.Name)
+		if err != nil {
+			return err
+		}
+		switch {
+		case hdr.IsDir:
+			if err = os.MkdirAll(LongPath(target), 0o755); err != nil {
+				return err
+			}
+			if err = os.Chmod(LongPath(target), mode.Perm()|0o700); err != nil {
+				return err
+			}
+			dt[target] = hdr.ModificationTime
+		case mode&fs.ModeSymlink != 0:
+			// not restored, as for zips
+			continue
+		default:
+			cfg.progress.entry(hdr.Name)
+			*written = append(*written, target)
+			if err = writeEntryFile(target, cfg.progress.reader(&rc.Reader), mode, hdr.ModificationTime); err != nil {
+				return rarError(err)
+			}
+		}
+	}
+	return dt.apply()
+}
+
+// RarList - list the entries of the rar src (all its volumes), WithPassword is needed for archives with encrypted
+// headers
+func RarList(src string, opts ...ArchiveOption) ([]ArchiveEntry, error) {
+	cfg := archiveOptions(opts)
+	files, err := rardecode.List(src, rarOptions(&cfg)...)
+	if err != nil {
+		return nil, rarError(err)
+	}
+	entries := make([]ArchiveEntry, 0, len(files))
+	for _, f := range files {
+		entries = append(entries, ArchiveEntry{
+			Name:      f.Name,
+			Size:      f.UnPackedSize,
+			Mode:      f.Mode(),
+			Modified:  f.ModificationTime,
+			Encrypted: f.Encrypted,
+		})
+	}
+	return entries, nil
+}
+
+// rarOptions - the decoder options, the volumes are opened with openArchive so split (.001) files work as well
+func rarOptions(cfg *archiveConfig) []rardecode.Option {
+	ropts := []rardecode.Option{rardecode.FileSystem(archiveFS{})}
+	if cfg.password != "" {
+		ropts = append(ropts, rardecode.Password(cfg.password))
+	}
+	return ropts
+}
+
+// rarError - map the decoder password errors to ErrPasswordRequired and ErrBadPassword
+func rarError(err error) error {
+	switch {
+	case errors.Is(err, rardecode.ErrArchiveEncrypted), errors.Is(err, rardecode.ErrArchivedFileEncrypted):
+		return fmt.Errorf("%w: %w", ErrPasswordRequired, err)
+	case errors.Is(err, rardecode.ErrBadPassword):
+		return fmt.Errorf("%w: %w", ErrBadPassword, err)
+	}
+	return err
+}
+
+// rarUnsupported - check if err is a decoder limitation rather than a broken archive
+func rarUnsupported(err error) bool {
+	return slices.ContainsFunc(rarUnsupportedErrs, func(e error) bool { return errors.Is(err, e) })
+}
+
+// rarExternal - the unrar, or else 7z, extractor, passing the password if there is one (on the command line, the
+// programs otherwise ask for it on the terminal)
+func rarExternal(password string) (*ExternalExtractor, error) {
+	x, err := NewUnrarExtractor()
+	if err != nil {
+		x, err = New7zExtractor()
+	}
+	if err != nil || password == "" {
+		return x, err
+	}
+	args := x.Args
+	x.Args = func(src, destDir string) []string {
+		a := args(src, destDir)
+		for i := range a {
+			if a[i] == "-p-" {
+				a[i] = "-p" + password
+			}
+		}
+		return a
+	}
+	return x, nil
+}
+
+// archiveFS - open files as openArchive does, for the decoders opening files by name
+type archiveFS struct{}
+
+func (archiveFS) Open(name string) (fs.File, error) {
+	return openArchive(name)
+}
@@ -0,0 +1,45 @@
+package razutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRemovePartial - a failed extraction is undone before the external fallback, what was there before is kept
+func TestRemovePartial(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "out")
+	os.MkdirAll(filepath.Join(dest, "keep"), 0o755)
+	os.WriteFile(filepath.Join(dest, "keep", "old.txt"), []byte("old"), 0o644)
+	before, err := dirNames(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	written := []string{filepath.Join(dest, "keep", "new.txt"), filepath.Join(dest, "sub", "a.txt")}
+	os.WriteFile(written[0], []byte("partial"), 0o644)
+	os.MkdirAll(filepath.Join(dest, "sub"), 0o755)
+	os.WriteFile(written[1], []byte("partial"), 0o644)
+	if err = removePartial(dest, before, written); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := os.ReadDir(dest)
+	if len(entries) != 1 || entries[0].Name() != "keep" {
+		t.Fatalf("left in destDir: %v", entries)
+	}
+	if _, err = os.Stat(filepath.Join(dest, "keep", "new.txt")); err == nil {
+		t.Fatal("written file not removed")
+	}
+	if _, err = os.Stat(filepath.Join(dest, "keep", "old.txt")); err != nil {
+		t.Fatal("existing file removed")
+	}
+	// a destDir that did not exist is removed completely
+	fresh := filepath.Join(t.TempDir(), "fresh")
+	before, _ = dirNames(fresh)
+	os.MkdirAll(filepath.Join(fresh, "x"), 0o755)
+	if err = removePartial(fresh, before, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(fresh); err == nil {
+		t.Fatal("new destDir not removed")
+	}
+}
@@ -0,0 +1,136 @@
+package razutils
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+/*
+RateLimiter - a token bucket rate limiter, safe to use from multiple goroutines.
+The bucket holds up to burst tokens and is refilled at rate tokens per second, each call takes one token.
+Allow does not block, Wait blocks until a token is available (or the context is done).
+*/
+
+type RateLimiter struct {
+	rate   float64 // tokens added per second
+	burst  float64
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+}
+
+// NewRateLimiter - create a limiter allowing rate calls per second with bursts of up to burst calls (minimum 1).
+// the bucket starts full.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	b := float64(max(burst, 1))
+	return &RateLimiter{rate: rate, burst: b, tokens: b, last: time.Now()}
+}
+
+// NewRateLimiterEvery - create a limiter allowing one call every interval with bursts of up to burst calls
+func NewRateLimiterEvery(interval time.Duration, burst int) *RateLimiter {
+	if interval <= 0 {
+		return NewRateLimiter(0, burst)
+	}
+	return NewRateLimiter(float64(time.Second)/float64(interval), burst)
+}
+
+// refillLocked - add the tokens accumulated since the last call (must be called under lock)
+func (r *RateLimiter) refillLocked(now time.Time) {
+	if el := now.Sub(r.last); el > 0 {
+		r.tokens = min(r.burst, r.tokens+el.Seconds()*r.rate)
+	}
+	r.last = now
+}
+
+// reserve - take a token, returning how long to wait until it is actually available (0 means now).
+// a rate of 0 or less means no limit.
+func (r *RateLimiter) reserve() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.rate <= 0 {
+		return 0
+	}
+	r.refillLocked(time.Now())
+	r.tokens--
+	if r.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-r.tokens / r.rate * float64(time.Second))
+}
+
+// Allow - take a token if one is available now, returns false (and takes nothing) if not
+func (r *RateLimiter) Allow() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.rate <= 0 {
+		return true
+	}
+	r.refillLocked(time.Now())
+	if r.tokens < 1 {
+		return false
+	}
+	r.tokens--
+	return true
+}
+
+// Wait - block until a token is available or ctx is done. on ctx error the token is returned to the bucket.
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	d := r.reserve()
+	if d == 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		r.mu.Lock()
+		r.tokens = min(r.burst, r.tokens+1)
+		r.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// SetRate - change the rate (tokens per second), the tokens accumulated so far are kept
+func (r *RateLimiter) SetRate(rate float64) {
+	r.mu.Lock()
+	r.refillLocked(time.Now())
+	r.rate = rate
+	r.mu.Unlock()
+}
+
+// SetBurst - change the bucket size
+func (r *RateLimiter) SetBurst(burst int) {
+	r.mu.Lock()
+	r.refillLocked(time.Now())
+	r.burst = float64(max(burst, 1))
+	r.tokens = min(r.tokens, r.burst)
+	r.mu.Unlock()
+}
+
+// Tokens - return the number of tokens currently available
+func (r *RateLimiter) Tokens() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refillLocked(time.Now())
+	return r.tokens
+}
+
+// RateLimited - wrap fn so every call waits for the limiter first, e.g.
+//
+//	fetch := RateLimited(lim, func(ctx context.Context, id string) (Sub, error) {...})
+//	sub, err := fetch(ctx, "1234")
+func RateLimited[A, R any](r *RateLimiter, fn func(ctx context.Context, arg A) (R, error)) func(ctx context.Context, arg A) (R, error) {
+	return func(ctx context.Context, arg A) (R, error) {
+		if err := r.Wait(ctx); err != nil {
+			var zero R
+			return zero, err
+		}
+		return fn(ctx, arg)
+	}
+}
@@ -0,0 +1,85 @@
+package razutils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+/*
+RecentBuffer - keep the last N events with the time they were added, for "last 100 events" diagnostics views.
+It is a RingBuffer of timestamped entries with formatting helpers, and is thread safe.
+*/
+
+// TimedEntry - a value with the time it was added
+type TimedEntry[T any] struct {
+	Time  time.Time
+	Value T
+}
+
+// String - format as "time value"
+func (e TimedEntry[T]) String() string {
+	return e.Time.Format("2006-01-02 15:04:05.000") + " " + fmt.Sprint(e.Value)
+}
+
+type RecentBuffer[T any] struct {
+	ring *RingBuffer[TimedEntry[T]]
+}
+
+// NewRecentBuffer - create a buffer keeping the last n entries
+func NewRecentBuffer[T any](n int) *RecentBuffer[T] {
+	return &RecentBuffer[T]{ring: NewRingBuffer[TimedEntry[T]](n)}
+}
+
+// Add - add a value stamped with the current time
+func (r *RecentBuffer[T]) Add(v T) {
+	r.ring.Add(TimedEntry[T]{Time: time.Now(), Value: v})
+}
+
+// Entries - return the entries, oldest first
+func (r *RecentBuffer[T]) Entries() []TimedEntry[T] {
+	return r.ring.Snapshot()
+}
+
+// Last - return up to the n newest entries, newest first
+func (r *RecentBuffer[T]) Last(n int) []TimedEntry[T] {
+	all := r.ring.Snapshot()
+	res := make([]TimedEntry[T], 0, min(n, len(all)))
+	for i := len(all) - 1; i >= 0 && len(res) < n; i-- {
+		res = append(res, all[i])
+	}
+	return res
+}
+
+// Since - return the entries added in the last d, oldest first
+func (r *RecentBuffer[T]) Since(d time.Duration) []TimedEntry[T] {
+	cutoff := time.Now().Add(-d)
+	all := r.ring.Snapshot()
+	for i, e := range all {
+		if !e.Time.Before(cutoff) {
+			return all[i:]
+		}
+	}
+	return []TimedEntry[T]{}
+}
+
+// Len - return the number of entries held
+func (r *RecentBuffer[T]) Len() int {
+	return r.ring.Len()
+}
+
+// Dump - format all the entries, oldest first, one "time value" per line
+func (r *RecentBuffer[T]) Dump() string {
+	return r.Format("2006-01-02 15:04:05.000")
+}
+
+// Format - format all the entries, oldest first, one per line with the time in the given layout
+func (r *RecentBuffer[T]) Format(layout string) string {
+	var b strings.Builder
+	for _, e := range r.ring.Snapshot() {
+		b.WriteString(e.Time.Format(layout))
+		b.WriteByte(' ')
+		fmt.Fprintln(&b, e.Value)
+	}
+	return b.String()
+}
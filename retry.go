@@ -0,0 +1,120 @@
+package razutils
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+/*
+Retry - call a function until it succeeds, waiting between the attempts with an exponential backoff and full jitter
+(a random wait between 0 and baseDelay*2^attempt, capped by the max delay).  Options control which errors are retried
+and allow logging each retry:
+
+	err := Retry(ctx, 5, 200*time.Millisecond, func(ctx context.Context) error { return copyToNas(ctx) },
+		RetryIf(isNetErr), OnRetry(func(n int, err error, wait time.Duration) { log.Println("retry", n, err) }))
+*/
+
+// RetryOption - an option for Retry
+type RetryOption func(*retryConfig)
+
+type retryConfig struct {
+	maxDelay  time.Duration
+	retryable func(error) bool
+	onRetry   func(attempt int, err error, wait time.Duration)
+}
+
+// RetryIf - retry only errors for which fn returns true (by default all errors but context ones are retried)
+func RetryIf(fn func(error) bool) RetryOption {
+	return func(c *retryConfig) { c.retryable = fn }
+}
+
+// OnRetry - call fn before waiting for each retry with the attempt number that failed (1 based), its error and the wait
+func OnRetry(fn func(attempt int, err error, wait time.Duration)) RetryOption {
+	return func(c *retryConfig) { c.onRetry = fn }
+}
+
+// RetryMaxDelay - cap the wait between attempts (default 1 minute)
+func RetryMaxDelay(d time.Duration) RetryOption {
+	return func(c *retryConfig) { c.maxDelay = d }
+}
+
+// permanentError - marks an error that must not be retried
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent - wrap an error returned to Retry so it stops at once. Retry returns the original error.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err}
+}
+
+// Retry - call fn up to attempts times until it returns nil. returns the last error, or the context error if ctx is
+// done while waiting.
+func Retry(ctx context.Context, attempts int, baseDelay time.Duration, fn func(ctx context.Context) error, opts ...RetryOption) error {
+	cfg := retryConfig{maxDelay: time.Minute}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		if attempt >= attempts || !isRetryable(&cfg, err) {
+			return err
+		}
+		wait := backoff(baseDelay, cfg.maxDelay, attempt)
+		if cfg.onRetry != nil {
+			cfg.onRetry(attempt, err, wait)
+		}
+		if err := SleepCtx(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// RetryValue - Retry for a function returning a value as well
+func RetryValue[T any](ctx context.Context, attempts int, baseDelay time.Duration, fn func(ctx context.Context) (T, error), opts ...RetryOption) (T, error) {
+	var res T
+	err := Retry(ctx, attempts, baseDelay, func(ctx context.Context) error {
+		var err error
+		res, err = fn(ctx)
+		return err
+	}, opts...)
+	return res, err
+}
+
+// isRetryable - check if err should be retried according to the config
+func isRetryable(cfg *retryConfig, err error) bool {
+	if cfg.retryable != nil {
+		return cfg.retryable(err)
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// backoff - the full jitter wait before the retry following attempt (1 based)
+func backoff(base, maxDelay time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	ceil := maxDelay
+	if attempt < 62 && base <= maxDelay>>(attempt-1) {
+		ceil = base << (attempt - 1)
+	}
+	if ceil <= 0 {
+		return 0
+	}
+	return RandDuration(0, ceil)
+}
@@ -0,0 +1,69 @@
+package razutils
+
+import (
+	"sync"
+)
+
+/*
+RingBuffer - a fixed capacity thread safe buffer keeping the last N items: when it is full adding an item
+overwrites the oldest one.  Good for keeping "the last 500 log lines" or recent events.
+*/
+
+type RingBuffer[T any] struct {
+	buf  []T
+	next int // where the next item goes
+	n    int
+	mu   sync.Mutex
+}
+
+// NewRingBuffer - create a ring buffer holding up to capacity items (at least 1)
+func NewRingBuffer[T any](capacity int) *RingBuffer[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &RingBuffer[T]{buf: make([]T, capacity)}
+}
+
+// Add - add an item, overwriting the oldest one if the buffer is full
+func (r *RingBuffer[T]) Add(item T) {
+	r.mu.Lock()
+	r.buf[r.next] = item
+	r.next = (r.next + 1) % len(r.buf)
+	if r.n < len(r.buf) {
+		r.n++
+	}
+	r.mu.Unlock()
+}
+
+// Snapshot - return a copy of the current items, oldest first
+func (r *RingBuffer[T]) Snapshot() []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := make([]T, r.n)
+	start := (r.next - r.n + len(r.buf)) % len(r.buf)
+	for i := 0; i < r.n; i++ {
+		res[i] = r.buf[(start+i)%len(r.buf)]
+	}
+	return res
+}
+
+// Len - return the number of items currently held
+func (r *RingBuffer[T]) Len() int {
+	r.mu.Lock()
+	res := r.n
+	r.mu.Unlock()
+	return res
+}
+
+// Cap - return the buffer capacity
+func (r *RingBuffer[T]) Cap() int {
+	return len(r.buf)
+}
+
+// Clear - remove all the items
+func (r *RingBuffer[T]) Clear() {
+	r.mu.Lock()
+	clear(r.buf)
+	r.next, r.n = 0, 0
+	r.mu.Unlock()
+}
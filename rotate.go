@@ -0,0 +1,159 @@
+package razutils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingWriter - an io.Writer writing into a file that is rotated when it reaches a size limit:
+// file.log is renamed to file.log.1, file.log.1 to file.log.2 and so on, keeping up to maxFiles old files.
+// With compress the rotated files are gzipped (file.log.1.gz ...).  It is safe for concurrent use, so it can be
+// given to log.SetOutput.
+type RotatingWriter struct {
+	mu       sync.Mutex
+	path     string
+	maxSize  int64
+	maxFiles int
+	compress bool
+	f        *os.File
+	size     int64
+	closed   bool
+}
+
+// NewRotatingWriter - open (append to) the file at path, rotating it whenever a write would take it above maxSize
+// bytes.  maxFiles is the number of rotated files kept (0 means the old data is simply dropped).
+func NewRotatingWriter(path string, maxSize int64, maxFiles int, compress bool) (*RotatingWriter, error) {
+	if maxSize <= 0 {
+		return nil, errors.New("RotatingWriter: maxSize must be positive")
+	}
+	w := &RotatingWriter{path: path, maxSize: maxSize, maxFiles: maxFiles, compress: compress}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *RotatingWriter) open() error {
+	f, err := os.OpenFile(LongPath(w.path), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.f, w.size = f, info.Size()
+	return nil
+}
+
+// Write - write p to the file, rotating first if p does not fit.  a single write larger than the limit is written
+// as is into a fresh file.  If the rotation fails (e.g. a full disk) p is still written into the active file, past
+// the limit, and the rotation error is returned; the rotation is tried again by the next writes.
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.ready(); err != nil {
+		return 0, err
+	}
+	var rerr error
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		rerr = w.rotate()
+		if w.f == nil {
+			return 0, rerr
+		}
+	}
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	if err == nil && rerr != nil {
+		err = fmt.Errorf("rotate %s: %w", w.path, rerr)
+	}
+	return n, err
+}
+
+// Rotate - force a rotation now
+func (w *RotatingWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.ready(); err != nil {
+		return err
+	}
+	return w.rotate()
+}
+
+// ready - check the writer is not closed, reopening the file if an earlier reopen failed
+func (w *RotatingWriter) ready() error {
+	if w.closed {
+		return os.ErrClosed
+	}
+	if w.f == nil {
+		return w.open()
+	}
+	return nil
+}
+
+// rotatedName - the name of the n-th rotated file
+func (w *RotatingWriter) rotatedName(n int) string {
+	name := fmt.Sprintf("%s.%d", w.path, n)
+	if w.compress {
+		name += ".gz"
+	}
+	return name
+}
+
+// rotate - rotate the files and open the new active file.  Whatever fails, the active file is reopened (the old one
+// when it was not moved) so the writer keeps working.
+func (w *RotatingWriter) rotate() error {
+	err := w.f.Close()
+	w.f = nil
+	if err == nil {
+		err = w.rotateFiles()
+	}
+	return errors.Join(err, w.open())
+}
+
+// rotateFiles - move the closed active file and the rotated ones one place up
+func (w *RotatingWriter) rotateFiles() error {
+	if w.maxFiles <= 0 {
+		if err := os.Remove(LongPath(w.path)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	if err := os.Remove(LongPath(w.rotatedName(w.maxFiles))); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for n := w.maxFiles - 1; n >= 1; n-- {
+		err := os.Rename(LongPath(w.rotatedName(n)), LongPath(w.rotatedName(n+1)))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if w.compress {
+		if err := GzipCompress(w.path, w.rotatedName(1)); err != nil {
+			os.Remove(LongPath(w.rotatedName(1))) // partly written, the data is still in the active file
+			return err
+		}
+		if err := os.Remove(LongPath(w.path)); err != nil {
+			return err
+		}
+	} else if err := os.Rename(LongPath(w.path), LongPath(w.rotatedName(1))); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Close - close the underlying file. further writes fail with os.ErrClosed.
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	if w.f == nil {
+		return nil
+	}
+	err := w.f.Close()
+	w.f = nil
+	return err
+}
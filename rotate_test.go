@@ -0,0 +1,45 @@
+package razutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRotatingWriterRotateFailure - a failed rotation keeps the writer writing into the active file, and the
+// rotation is done once it can be
+func TestRotatingWriterRotateFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	w, err := NewRotatingWriter(path, 10, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	// a non empty directory where the rotated file goes makes the rotation fail
+	blocker := path + ".1.gz"
+	os.MkdirAll(filepath.Join(blocker, "x"), 0o755)
+	if _, err = w.Write([]byte("0123456789")); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := w.Write([]byte("abc")); err == nil || n != 3 {
+		t.Fatalf("expected the rotation error with the data written, got %d %v", n, err)
+	}
+	if _, err = w.Write([]byte("def")); err == nil {
+		t.Fatal("expected the rotation to be tried again and fail")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "0123456789abcdef" {
+		t.Fatalf("active file %q", data)
+	}
+	os.RemoveAll(blocker)
+	if _, err = w.Write([]byte("ghi")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "ghi" {
+		t.Fatalf("active file after rotation %q", data)
+	}
+	w.Close()
+	if _, err = w.Write([]byte("x")); err != os.ErrClosed {
+		t.Fatalf("write after close: %v", err)
+	}
+}
@@ -0,0 +1,262 @@
+package razutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+Scheduler - run background jobs on an interval or on a cron expression, replacing hand rolled time.Ticker loops.
+
+	s := NewScheduler()
+	s.Every("scan", 10*time.Minute, scanLibrary)
+	s.Cron("cleanup", "30 3 * * *", cleanup, WithOverlap(OverlapQueue))
+	s.Start(ctx)
+	...
+	s.Stop() // waits for the running jobs
+
+Each job has an overlap policy deciding what happens when it is due while its previous run is still going:
+OverlapSkip (the default) drops that run, OverlapQueue runs it once the current run ends (at most one pending run).
+Interval jobs are scheduled from their start times so they do not drift.
+*/
+
+// OverlapPolicy - what to do when a job is due while it is still running
+type OverlapPolicy int
+
+const (
+	OverlapSkip  OverlapPolicy = iota // skip the run
+	OverlapQueue                      // run again right after the current run (pending runs are coalesced to one)
+)
+
+// ErrJobExists - a job with that name is already scheduled
+var ErrJobExists = errors.New("job already exists")
+
+// JobOption - an option for the scheduled jobs
+type JobOption func(*schedJob)
+
+// WithOverlap - set the job overlap policy
+func WithOverlap(p OverlapPolicy) JobOption {
+	return func(j *schedJob) { j.overlap = p }
+}
+
+// RunAtStart - run an interval job once when the scheduler starts, instead of after the first interval
+func RunAtStart() JobOption {
+	return func(j *schedJob) { j.atStart = true }
+}
+
+// JobInfo - the state of a scheduled job
+type JobInfo struct {
+	Name    string
+	LastRun time.Time // the start of the last run, zero if it never ran
+	LastErr error     // the error returned by the last run
+	NextRun time.Time // zero if not scheduled (scheduler not started or cron never matches)
+	Running bool
+	Runs    int // completed runs
+	Skipped int // runs skipped due to overlap
+}
+
+type schedJob struct {
+	name     string
+	fn       func(ctx context.Context) error
+	interval time.Duration
+	cron     *CronSchedule
+	overlap  OverlapPolicy
+	atStart  bool
+	info     JobInfo
+	pending  bool
+	stop     chan struct{}
+}
+
+// nextAfter - the next run time after t
+func (j *schedJob) nextAfter(t time.Time) time.Time {
+	if j.cron != nil {
+		return j.cron.Next(t)
+	}
+	return t.Add(j.interval)
+}
+
+type Scheduler struct {
+	jobs    map[string]*schedJob
+	ctx     context.Context
+	cancel  context.CancelFunc
+	started bool
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+}
+
+// NewScheduler - create an empty scheduler
+func NewScheduler() *Scheduler {
+	return &Scheduler{jobs: make(map[string]*schedJob)}
+}
+
+// Every - add a job running fn every interval
+func (s *Scheduler) Every(name string, interval time.Duration, fn func(ctx context.Context) error, opts ...JobOption) error {
+	if interval <= 0 {
+		return fmt.Errorf("job %s: interval must be positive", name)
+	}
+	return s.add(&schedJob{name: name, fn: fn, interval: interval}, opts)
+}
+
+// Cron - add a job running fn on a cron expression (see ParseCron)
+func (s *Scheduler) Cron(name, expr string, fn func(ctx context.Context) error, opts ...JobOption) error {
+	c, err := ParseCron(expr)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", name, err)
+	}
+	return s.add(&schedJob{name: name, fn: fn, cron: c}, opts)
+}
+
+func (s *Scheduler) add(j *schedJob, opts []JobOption) error {
+	for _, o := range opts {
+		o(j)
+	}
+	j.info.Name = j.name
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[j.name]; ok {
+		return fmt.Errorf("%w: %s", ErrJobExists, j.name)
+	}
+	s.jobs[j.name] = j
+	if s.started {
+		s.launchLocked(j)
+	}
+	return nil
+}
+
+// Remove - remove a job, a run in progress is not interrupted. returns false if there is no such job
+func (s *Scheduler) Remove(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[name]
+	if !ok {
+		return false
+	}
+	delete(s.jobs, name)
+	if j.stop != nil {
+		close(j.stop)
+	}
+	return true
+}
+
+// Start - start running the jobs. cancelling ctx stops the scheduler (like Stop, without waiting)
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	for _, j := range s.jobs {
+		s.launchLocked(j)
+	}
+}
+
+// Stop - stop scheduling, cancel the context of the running jobs and wait for them to return. it can be started again
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.started, s.cancel = false, nil
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// launchLocked - start the timer goroutine of a job (must be called under lock)
+func (s *Scheduler) launchLocked(j *schedJob) {
+	j.stop = make(chan struct{})
+	first := j.nextAfter(time.Now())
+	if j.atStart && j.cron == nil {
+		first = time.Now()
+	}
+	j.info.NextRun = first
+	s.wg.Add(1)
+	go s.loop(s.ctx, j, j.stop, first)
+}
+
+// loop - wait for the job run times and start the runs
+func (s *Scheduler) loop(ctx context.Context, j *schedJob, stop chan struct{}, next time.Time) {
+	defer s.wg.Done()
+	for !next.IsZero() {
+		t := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-stop:
+			t.Stop()
+			return
+		case <-t.C:
+		}
+		due := next
+		next = j.nextAfter(due)
+		if now := time.Now(); !next.After(now) {
+			// we fell behind (a sleeping machine etc.), skip the missed runs
+			next = j.nextAfter(now)
+		}
+		s.mu.Lock()
+		j.info.NextRun = next
+		switch {
+		case !j.info.Running:
+			j.info.Running = true
+			s.wg.Add(1)
+			go s.run(ctx, j)
+		case j.overlap == OverlapQueue:
+			j.pending = true
+		default:
+			j.info.Skipped++
+		}
+		s.mu.Unlock()
+	}
+}
+
+// run - run the job, and again while runs are pending
+func (s *Scheduler) run(ctx context.Context, j *schedJob) {
+	defer s.wg.Done()
+	for {
+		s.mu.Lock()
+		j.info.LastRun = time.Now()
+		s.mu.Unlock()
+		err := safeRun(func() error { return j.fn(ctx) })
+		s.mu.Lock()
+		j.info.LastErr = err
+		j.info.Runs++
+		if !j.pending || ctx.Err() != nil {
+			j.pending = false
+			j.info.Running = false
+			s.mu.Unlock()
+			return
+		}
+		j.pending = false
+		s.mu.Unlock()
+	}
+}
+
+// Info - return the state of a job, false if there is no such job
+func (s *Scheduler) Info(name string) (JobInfo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[name]
+	if !ok {
+		return JobInfo{}, false
+	}
+	return j.info, true
+}
+
+// Jobs - return the state of all the jobs sorted by name
+func (s *Scheduler) Jobs() []JobInfo {
+	s.mu.Lock()
+	res := make([]JobInfo, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		res = append(res, j.info)
+	}
+	s.mu.Unlock()
+	slices.SortFunc(res, func(a, b JobInfo) int { return strings.Compare(a.Name, b.Name) })
+	return res
+}
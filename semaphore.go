@@ -0,0 +1,121 @@
+package razutils
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+/*
+Semaphore - a weighted counting semaphore, e.g. to cap the number of concurrent ffmpeg processes or open files.
+Waiters are served in FIFO order, so a large request is not starved by a stream of small ones.
+*/
+
+type Semaphore struct {
+	size    int64
+	cur     int64
+	waiters list.List // of *semWaiter
+	mu      sync.Mutex
+}
+
+type semWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// NewSemaphore - create a semaphore with a total weight of size
+func NewSemaphore(size int64) *Semaphore {
+	return &Semaphore{size: size}
+}
+
+// Acquire - take n units, blocking until they are available or ctx is done (then nothing is taken and the context
+// error is returned).  asking for more than the semaphore size fails only when ctx is done.
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	s.mu.Lock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.mu.Unlock()
+		return nil
+	}
+	w := &semWaiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		select {
+		case <-w.ready:
+			// got it just as ctx was done, give it back
+			s.cur -= n
+			s.notifyLocked()
+		default:
+			front := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			if front {
+				// the waiters behind us may fit now
+				s.notifyLocked()
+			}
+		}
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// TryAcquire - take n units if available now without blocking, returns false if not
+func (s *Semaphore) TryAcquire(n int64) bool {
+	s.mu.Lock()
+	ok := s.size-s.cur >= n && s.waiters.Len() == 0
+	if ok {
+		s.cur += n
+	}
+	s.mu.Unlock()
+	return ok
+}
+
+// Release - give back n units. releasing more than was acquired panics.
+func (s *Semaphore) Release(n int64) {
+	s.mu.Lock()
+	s.cur -= n
+	if s.cur < 0 {
+		s.mu.Unlock()
+		panic("semaphore: released more than held")
+	}
+	s.notifyLocked()
+	s.mu.Unlock()
+}
+
+// notifyLocked - wake the waiters at the front that now fit, in order (must be called under lock)
+func (s *Semaphore) notifyLocked() {
+	for {
+		e := s.waiters.Front()
+		if e == nil {
+			return
+		}
+		w := e.Value.(*semWaiter)
+		if s.size-s.cur < w.n {
+			return
+		}
+		s.cur += w.n
+		s.waiters.Remove(e)
+		close(w.ready)
+	}
+}
+
+// Do - run fn holding n units of the semaphore
+func (s *Semaphore) Do(ctx context.Context, n int64, fn func() error) error {
+	if err := s.Acquire(ctx, n); err != nil {
+		return err
+	}
+	defer s.Release(n)
+	return fn()
+}
+
+// InUse - return the number of units currently held
+func (s *Semaphore) InUse() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cur
+}
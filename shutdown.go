@@ -0,0 +1,179 @@
+package razutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+/*
+Shutdown - the graceful shutdown scaffolding of a daemon.  Components run under the root context and register
+cleanup funcs with a priority.  On SIGINT/SIGTERM (NotifySignals) or Trigger, the root context is cancelled and the
+cleanups are run, higher priorities first, those with the same priority concurrently, all within the timeout.
+Cleanups still running when it passes are reported as stragglers in the error returned by Wait.
+
+	sd := NewShutdown(context.Background(), 10*time.Second)
+	defer sd.NotifySignals()()
+	go server.Run(sd.Context())
+	sd.Register("db", 0, func(ctx context.Context) error { return db.Close() })
+	sd.Register("http", 10, srv.Shutdown) // before the db
+	Exit(sd.Wait())
+*/
+
+// ErrShutdownTimeout - the cleanups did not all finish within the shutdown timeout
+var ErrShutdownTimeout = errors.New("shutdown timeout")
+
+type shutdownHook struct {
+	name     string
+	priority int
+	fn       func(ctx context.Context) error
+}
+
+type Shutdown struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	timeout time.Duration
+	hooks   []shutdownHook
+	once    sync.Once
+	done    chan struct{} // closed once all the cleanups finished (or timed out)
+	err     error
+	mu      sync.Mutex
+}
+
+// NewShutdown - create a manager with a root context derived from parent and the total time allowed for the cleanups
+func NewShutdown(parent context.Context, timeout time.Duration) *Shutdown {
+	ctx, cancel := context.WithCancel(parent)
+	return &Shutdown{ctx: ctx, cancel: cancel, timeout: timeout, done: make(chan struct{})}
+}
+
+// Context - the root context, cancelled when the shutdown starts
+func (s *Shutdown) Context() context.Context {
+	return s.ctx
+}
+
+// Register - add a cleanup. higher priorities run first. the ctx passed to fn expires with the shutdown timeout.
+func (s *Shutdown) Register(name string, priority int, fn func(ctx context.Context) error) {
+	s.mu.Lock()
+	s.hooks = append(s.hooks, shutdownHook{name: name, priority: priority, fn: fn})
+	s.mu.Unlock()
+}
+
+// NotifySignals - start the shutdown on SIGINT or SIGTERM.  a second signal exits the process at once with
+// ExitFailure.  The returned func stops listening.
+func (s *Shutdown) NotifySignals() func() {
+	ch := make(chan os.Signal, 2)
+	done := make(chan struct{})
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-ch:
+			log.Println("shutdown: got", sig)
+			s.Trigger()
+		case <-done:
+			return
+		}
+		select {
+		case sig := <-ch:
+			log.Println("shutdown: forced exit on second", sig)
+			os.Exit(ExitFailure)
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
+
+// Trigger - start the shutdown (only the first call does anything). it does not wait, use Wait for that
+func (s *Shutdown) Trigger() {
+	s.once.Do(func() {
+		s.cancel()
+		go s.run()
+	})
+}
+
+// Wait - wait for the shutdown to start (by a signal or Trigger, or the parent context being done) and complete.
+// returns the cleanup errors joined, with ErrShutdownTimeout naming the stragglers if the timeout passed.
+func (s *Shutdown) Wait() error {
+	select {
+	case <-s.ctx.Done():
+		s.Trigger()
+	case <-s.done:
+	}
+	<-s.done
+	return s.err
+}
+
+// Done - a channel closed once the shutdown completed
+func (s *Shutdown) Done() <-chan struct{} {
+	return s.done
+}
+
+// run - run the cleanups by priority groups
+func (s *Shutdown) run() {
+	defer close(s.done)
+	s.mu.Lock()
+	hooks := slices.Clone(s.hooks)
+	s.mu.Unlock()
+	slices.SortStableFunc(hooks, func(a, b shutdownHook) int { return b.priority - a.priority })
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	var errs []error
+	var errMu sync.Mutex
+	running := make(map[string]bool)
+	for i := 0; i < len(hooks); {
+		j := i
+		for j < len(hooks) && hooks[j].priority == hooks[i].priority {
+			j++
+		}
+		var wg sync.WaitGroup
+		for _, h := range hooks[i:j] {
+			errMu.Lock()
+			running[h.name] = true
+			errMu.Unlock()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := safeRun(func() error { return h.fn(ctx) })
+				errMu.Lock()
+				delete(running, h.name)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+				}
+				errMu.Unlock()
+			}()
+		}
+		groupDone := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(groupDone)
+		}()
+		select {
+		case <-groupDone:
+		case <-ctx.Done():
+			errMu.Lock()
+			stragglers := slices.Sorted(maps.Keys(running))
+			// the lower priority cleanups never started
+			for _, h := range hooks[j:] {
+				stragglers = append(stragglers, h.name)
+			}
+			errs = append(errs, fmt.Errorf("%w, not finished: %s", ErrShutdownTimeout, strings.Join(stragglers, ", ")))
+			s.err = errors.Join(errs...)
+			errMu.Unlock()
+			return
+		}
+		i = j
+	}
+	s.err = errors.Join(errs...)
+}
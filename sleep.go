@@ -0,0 +1,49 @@
+package razutils
+
+import (
+	"context"
+	"time"
+)
+
+// SleepCtx - sleep for d or until ctx is done, whichever comes first. returns the context error if it was cut short
+func SleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WithTimeoutRun - run fn with a context that is cancelled after d.  fn should return once its context is done, if
+// it does not, WithTimeoutRun still returns (context.DeadlineExceeded) after d and leaves fn running in the background.
+func WithTimeoutRun(d time.Duration, fn func(ctx context.Context) error) error {
+	return WithTimeoutRunCtx(context.Background(), d, fn)
+}
+
+// WithTimeoutRunCtx - WithTimeoutRun with a parent context
+func WithTimeoutRunCtx(parent context.Context, d time.Duration, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(parent, d)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- safeRun(func() error { return fn(ctx) })
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		// give fn a last chance if it finished at the same moment
+		select {
+		case err := <-done:
+			return err
+		default:
+			return ctx.Err()
+		}
+	}
+}
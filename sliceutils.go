@@ -0,0 +1,74 @@
+package razutils
+
+// Chunk - split a slice into batches of up to size items (the last one may be shorter). the batches share the
+// memory of s.
+func Chunk[T any](s []T, size int) [][]T {
+	if size < 1 {
+		size = 1
+	}
+	chunks := make([][]T, 0, (len(s)+size-1)/size)
+	for len(s) > size {
+		chunks = append(chunks, s[:size:size])
+		s = s[size:]
+	}
+	if len(s) > 0 {
+		chunks = append(chunks, s)
+	}
+	return chunks
+}
+
+// Unique - return the items of s without duplicates, keeping the first occurrence order
+func Unique[T comparable](s []T) []T {
+	seen := make(map[T]struct{}, len(s))
+	res := make([]T, 0, len(s))
+	for _, v := range s {
+		if _, ok := seen[v]; !ok {
+			seen[v] = struct{}{}
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// Intersect - return the unique items that are in both a and b, in the order of a
+func Intersect[T comparable](a []T, b []T) []T {
+	inB := make(map[T]struct{}, len(b))
+	for _, v := range b {
+		inB[v] = struct{}{}
+	}
+	res := []T{}
+	for _, v := range Unique(a) {
+		if _, ok := inB[v]; ok {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// Difference - return the unique items of a that are not in b, in the order of a
+func Difference[T comparable](a []T, b []T) []T {
+	inB := make(map[T]struct{}, len(b))
+	for _, v := range b {
+		inB[v] = struct{}{}
+	}
+	res := []T{}
+	for _, v := range Unique(a) {
+		if _, ok := inB[v]; !ok {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// Union - return the unique items of a and then of b
+func Union[T comparable](a []T, b []T) []T {
+	return Unique(append(append(make([]T, 0, len(a)+len(b)), a...), b...))
+}
+
+// Shuffle - shuffle the slice in place (Fisher-Yates)
+func Shuffle[T any](s []T) {
+	for i := len(s) - 1; i > 0; i-- {
+		j := RandIntn(i + 1)
+		s[i], s[j] = s[j], s[i]
+	}
+}
@@ -0,0 +1,358 @@
+package razutils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+Split archives: a file cut into numbered parts ("movie.zip.001", "movie.zip.002" ...) of a fixed size, as done by
+7-Zip, HJSplit and the split command, to get around file size limits.  The parts are plain byte slices, joining
+them gives back the original file.  The extract functions read the parts as one file, given either the first part
+or the archive name without the number.
+*/
+
+// ErrMissingPart - a split archive with a part missing in its numbering
+var ErrMissingPart = errors.New("split archive part missing")
+
+// WithSplit - write the created archive (ZipCreate, TarCreate) as parts of at most partSize bytes named dest.001,
+// dest.002 ...
+func WithSplit(partSize int64) ArchiveOption {
+	return func(c *archiveConfig) { c.split = partSize }
+}
+
+// partName - the path of part n (1 based) of the split file base
+func partName(base string, n int) string {
+	return fmt.Sprintf("%s.%03d", base, n)
+}
+
+// partNumber - the part number of path and the path without it, ok is false if path is not named as a part
+func partNumber(path string) (base string, n int, ok bool) {
+	ext := filepath.Ext(path)
+	if len(ext) < 4 || strings.Trim(ext[1:], "0123456789") != "" {
+		return path, 0, false
+	}
+	n, err := strconv.Atoi(ext[1:])
+	if err != nil || n < 1 {
+		return path, 0, false
+	}
+	return path[:len(path)-len(ext)], n, true
+}
+
+// FindParts - list the parts of a split file in order, path is either one of the parts ("movie.zip.001") or the
+// name without the part number ("movie.zip").  The parts must be numbered from 1 with no gaps, otherwise
+// ErrMissingPart is returned.
+func FindParts(path string) ([]string, error) {
+	if base, _, ok := partNumber(path); ok {
+		path = base
+	}
+	dir, name := filepath.Split(path)
+	entries, err := os.ReadDir(LongPath(filepath.Clean(dir + ".")))
+	if err != nil {
+		return nil, err
+	}
+	var nums []int
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), name+".") {
+			continue
+		}
+		if base, n, ok := partNumber(e.Name()); ok && base == name {
+			nums = append(nums, n)
+		}
+	}
+	if len(nums) == 0 {
+		return nil, fmt.Errorf("no parts of %s: %w", path, os.ErrNotExist)
+	}
+	slices.Sort(nums)
+	parts := make([]string, len(nums))
+	for i, n := range nums {
+		if n != i+1 {
+			return nil, fmt.Errorf("%w: %s", ErrMissingPart, partName(path, i+1))
+		}
+		parts[i] = filepath.Join(dir, partName(name, n))
+	}
+	return parts, nil
+}
+
+// SplitFile - cut src into parts of at most partSize bytes named src.001, src.002 ..., src is not removed.  The
+// paths of the parts are returned.
+func SplitFile(src string, partSize int64) ([]string, error) {
+	if partSize <= 0 {
+		return nil, errors.New("SplitFile: part size must be positive")
+	}
+	in, err := os.Open(LongPath(src))
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	sw := newSplitWriter(src, partSize)
+	if _, err = io.Copy(sw, pausable(in)); err != nil {
+		sw.Close()
+		return nil, err
+	}
+	if err = sw.Close(); err != nil {
+		return nil, err
+	}
+	return sw.parts, nil
+}
+
+// JoinParts - join the parts of the split file at path (see FindParts) into dest
+func JoinParts(path string, dest string) error {
+	parts, err := FindParts(path)
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(LongPath(dest))
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	for _, p := range parts {
+		in, err := os.Open(LongPath(p))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, pausable(in))
+		in.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return out.Close()
+}
+
+// splitWriter - write into numbered parts of base, starting a new part every size bytes
+type splitWriter struct {
+	base    string
+	size    int64
+	cur     *os.File
+	written int64 // into the current part
+	parts   []string
+}
+
+func newSplitWriter(base string, size int64) *splitWriter {
+	return &splitWriter{base: base, size: size}
+}
+
+func (sw *splitWriter) Write(b []byte) (int, error) {
+	total := 0
+	for len(b) > 0 {
+		if sw.cur == nil || sw.written == sw.size {
+			if err := sw.next(); err != nil {
+				return total, err
+			}
+		}
+		chunk := b[:min(int64(len(b)), sw.size-sw.written)]
+		n, err := sw.cur.Write(chunk)
+		total += n
+		sw.written += int64(n)
+		if err != nil {
+			return total, err
+		}
+		b = b[n:]
+	}
+	return total, nil
+}
+
+// next - close the current part and start the next one
+func (sw *splitWriter) next() error {
+	if sw.cur != nil {
+		if err := sw.cur.Close(); err != nil {
+			return err
+		}
+	}
+	name := partName(sw.base, len(sw.parts)+1)
+	f, err := os.Create(LongPath(name))
+	if err != nil {
+		sw.cur = nil
+		return err
+	}
+	sw.cur, sw.written = f, 0
+	sw.parts = append(sw.parts, name)
+	return nil
+}
+
+// Close - close the last part and remove stale parts left after it by an earlier, longer, split of the same name.
+// closing again does nothing.
+func (sw *splitWriter) Close() error {
+	if sw.cur == nil && len(sw.parts) == 0 {
+		// nothing written, still make an (empty) first part
+		if err := sw.next(); err != nil {
+			return err
+		}
+	}
+	if sw.cur == nil {
+		return nil
+	}
+	err := sw.cur.Close()
+	sw.cur = nil
+	for n := len(sw.parts) + 1; ; n++ {
+		if os.Remove(LongPath(partName(sw.base, n))) != nil {
+			break
+		}
+	}
+	return err
+}
+
+// isArchiveOrPart - check if path is the archive at destAbs (absolute) or one of its parts, for walks over a tree the
+// archive is written into
+func isArchiveOrPart(path string, destAbs string) bool {
+	a := absClean(path)
+	b, _, ok := partNumber(a)
+	return a == destAbs || ok && b == destAbs
+}
+
+// createArchiveFile - create the archive file dest, or its first part with WithSplit
+func createArchiveFile(dest string, cfg archiveConfig) (io.WriteCloser, error) {
+	if cfg.split > 0 {
+		return newSplitWriter(dest, cfg.split), nil
+	}
+	return os.Create(LongPath(dest))
+}
+
+// archiveReader - an archive to extract, a single file or all the parts of a split archive read as one
+type archiveReader struct {
+	name  string   // the archive path, without the part number
+	parts []string // the part paths, a single one for a plain file
+	files []*os.File
+	ends  []int64 // the offset just after each part
+	pos   int64
+	mtime time.Time
+}
+
+// openArchive - open the archive at path, for split archives path is either a part or the name without the number
+// (when no such file exists)
+func openArchive(path string) (*archiveReader, error) {
+	parts := []string{path}
+	if _, n, ok := partNumber(path); ok {
+		p, err := FindParts(path)
+		if err == nil {
+			parts = p
+		} else if n == 1 {
+			return nil, err
+		}
+		// otherwise a file with a numbered extension ("backup.2024") that is not split
+	} else if _, err := os.Stat(LongPath(path)); errors.Is(err, os.ErrNotExist) {
+		if p, perr := FindParts(path); perr == nil {
+			parts = p
+		}
+	}
+	ar := &archiveReader{name: path, parts: parts}
+	if len(parts) > 1 || parts[0] != path {
+		ar.name, _, _ = partNumber(parts[0])
+	}
+	var size int64
+	for _, p := range parts {
+		f, err := os.Open(LongPath(p))
+		if err != nil {
+			ar.Close()
+			return nil, err
+		}
+		ar.files = append(ar.files, f)
+		info, err := f.Stat()
+		if err != nil {
+			ar.Close()
+			return nil, err
+		}
+		size += info.Size()
+		ar.ends = append(ar.ends, size)
+		if info.ModTime().After(ar.mtime) {
+			ar.mtime = info.ModTime()
+		}
+	}
+	return ar, nil
+}
+
+// Size - the total size of the archive
+func (ar *archiveReader) Size() int64 {
+	return ar.ends[len(ar.ends)-1]
+}
+
+func (ar *archiveReader) ReadAt(b []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("archiveReader: negative offset")
+	}
+	total := 0
+	for len(b) > 0 {
+		i, _ := slices.BinarySearch(ar.ends, off+1)
+		if i >= len(ar.files) {
+			return total, io.EOF
+		}
+		start := int64(0)
+		if i > 0 {
+			start = ar.ends[i-1]
+		}
+		chunk := b[:min(int64(len(b)), ar.ends[i]-off)]
+		n, err := ar.files[i].ReadAt(chunk, off-start)
+		total += n
+		off += int64(n)
+		b = b[n:]
+		if err != nil && !(errors.Is(err, io.EOF) && n == len(chunk)) {
+			if errors.Is(err, io.EOF) {
+				// the part is shorter than when it was opened
+				err = io.ErrUnexpectedEOF
+			}
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func (ar *archiveReader) Read(b []byte) (int, error) {
+	n, err := ar.ReadAt(b, ar.pos)
+	ar.pos += int64(n)
+	if n > 0 && errors.Is(err, io.EOF) {
+		err = nil
+	}
+	return n, err
+}
+
+func (ar *archiveReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += ar.pos
+	case io.SeekEnd:
+		offset += ar.Size()
+	default:
+		return 0, errors.New("archiveReader: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("archiveReader: negative position")
+	}
+	ar.pos = offset
+	return offset, nil
+}
+
+// Stat - the info of the first part with the size of the whole archive
+func (ar *archiveReader) Stat() (fs.FileInfo, error) {
+	info, err := ar.files[0].Stat()
+	if err != nil {
+		return nil, err
+	}
+	return archiveInfo{FileInfo: info, size: ar.Size()}, nil
+}
+
+type archiveInfo struct {
+	fs.FileInfo
+	size int64
+}
+
+func (ai archiveInfo) Size() int64 { return ai.size }
+
+func (ar *archiveReader) Close() error {
+	var errs []error
+	for _, f := range ar.files {
+		errs = append(errs, f.Close())
+	}
+	ar.files = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,176 @@
+package razutils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+/*
+SubRip (.srt) subtitles: parsing into cues and writing them back.  Real world files are messy (BOMs, UTF-16 or
+Windows-1255 text, CRLF, missing blank lines, bad or missing indices, '.' for the millisecond separator) and are read
+as well as possible.
+*/
+
+// ErrInvalidSRT - the content has text but no subtitle cue in it
+var ErrInvalidSRT = errors.New("no subtitle cues found")
+
+// Cue - a subtitle: its number, the time it is shown and its text lines
+type Cue struct {
+	Index int
+	Start time.Duration
+	End   time.Duration
+	Lines []string
+}
+
+// ParseSRT - parse SubRip subtitles into cues.  The text charset is detected as by ReadFileUTF8 and line endings are
+// normalized.  A cue missing its index, or with one that is not a number, gets the index after the previous cue.
+// Blank lines within the text are dropped, and text with no cue timing before it is ignored.  ErrInvalidSRT is
+// returned for content with text but no cues.
+func ParseSRT(r io.Reader) ([]Cue, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text, _, err := decodeText(data)
+	if err != nil {
+		return nil, err
+	}
+	lines := splitLines(text)
+	cues := []Cue{}
+	index, hasIndex := 0, false
+	blank := true // the previous line was blank, or this is the start
+	for i, line := range lines {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if line == "" {
+			blank = true
+			continue
+		}
+		if start, end, ok := parseSRTTiming(line); ok {
+			c := Cue{Index: 1, Start: start, End: end}
+			switch {
+			case hasIndex:
+				c.Index = index
+			case len(cues) > 0:
+				c.Index = cues[len(cues)-1].Index + 1
+			}
+			cues = append(cues, c)
+			hasIndex, blank = false, false
+			continue
+		}
+		if i+1 < len(lines) && isSRTTiming(lines[i+1]) {
+			n, err := strconv.Atoi(strings.TrimSpace(line))
+			if err == nil {
+				index, hasIndex = n, true
+				continue
+			}
+			if blank {
+				continue // a malformed index
+			}
+		}
+		if len(cues) > 0 {
+			c := &cues[len(cues)-1]
+			c.Lines = append(c.Lines, line)
+		}
+		blank = false
+	}
+	if len(cues) == 0 && strings.TrimSpace(text) != "" {
+		return nil, ErrInvalidSRT
+	}
+	return cues, nil
+}
+
+func isSRTTiming(line string) bool {
+	_, _, ok := parseSRTTiming(line)
+	return ok
+}
+
+// parseSRTTiming - parse "00:01:02,345 --> 00:01:04,000", anything after the end time (position coordinates) is
+// ignored
+func parseSRTTiming(line string) (start time.Duration, end time.Duration, ok bool) {
+	from, to, found := strings.Cut(line, "-->")
+	if !found {
+		return 0, 0, false
+	}
+	fields := strings.Fields(to)
+	if len(fields) == 0 {
+		return 0, 0, false
+	}
+	if start, ok = parseSRTTime(from); !ok {
+		return 0, 0, false
+	}
+	if end, ok = parseSRTTime(fields[0]); !ok {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// parseSRTTime - parse "hh:mm:ss,mmm", also accepting '.' for ',', no hours, and fewer or more fraction digits
+func parseSRTTime(s string) (time.Duration, bool) {
+	s = strings.TrimSpace(s)
+	var frac string
+	if i := strings.LastIndexAny(s, ",."); i >= 0 {
+		s, frac = s[:i], s[i+1:]
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, false
+	}
+	var d time.Duration
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		d = d*60 + time.Duration(n)
+	}
+	d *= time.Second
+	if frac != "" {
+		frac = (frac + "00")[:3]
+		ms, err := strconv.Atoi(frac)
+		if err != nil || ms < 0 {
+			return 0, false
+		}
+		d += time.Duration(ms) * time.Millisecond
+	}
+	return d, true
+}
+
+// WriteSRT - write cues as SubRip subtitles, numbered from 1 in their order (Index is not used) with LF line endings.
+// Empty text lines are skipped as a blank line ends a cue, and negative times are written as 0.
+func WriteSRT(w io.Writer, cues []Cue) error {
+	return writeSRT(w, cues, "\n")
+}
+
+// WriteSRTCRLF - same as WriteSRT but with windows CRLF line endings, for players that need them
+func WriteSRTCRLF(w io.Writer, cues []Cue) error {
+	return writeSRT(w, cues, "\r\n")
+}
+
+func writeSRT(w io.Writer, cues []Cue, eol string) error {
+	bw := bufio.NewWriter(w)
+	for i, c := range cues {
+		fmt.Fprintf(bw, "%d%s%s --> %s%s", i+1, eol, formatSRTTime(c.Start), formatSRTTime(c.End), eol)
+		for _, l := range c.Lines {
+			if strings.TrimSpace(l) == "" {
+				continue
+			}
+			bw.WriteString(l)
+			bw.WriteString(eol)
+		}
+		bw.WriteString(eol)
+	}
+	return bw.Flush()
+}
+
+// formatSRTTime - format a time as "hh:mm:ss,mmm"
+func formatSRTTime(d time.Duration) string {
+	d = max(d, 0)
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
@@ -0,0 +1,68 @@
+package razutils
+
+import (
+	"sync"
+)
+
+/*
+Stack - a thread safe LIFO stack mirroring the Queue API, all access is done under a Mutex.
+*/
+
+type Stack[T any] struct {
+	data []T
+	mu   sync.Mutex
+}
+
+// MakeStack - create a new Stack with a starting capacity
+func MakeStack[T any](initSize int) Stack[T] {
+	if initSize <= 0 {
+		return Stack[T]{}
+	}
+	return Stack[T]{data: make([]T, 0, initSize)}
+}
+
+// Push - push an item on top of the stack
+func (s *Stack[T]) Push(item T) {
+	s.mu.Lock()
+	s.data = append(s.data, item)
+	s.mu.Unlock()
+}
+
+// Pop - return the top (i.e. the newest) item while removing it. Error is returned if the stack is empty
+func (s *Stack[T]) Pop() (T, error) {
+	var zero T
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.data) == 0 {
+		return zero, ErrQueueEmpty
+	}
+	n := len(s.data) - 1
+	item := s.data[n]
+	s.data[n] = zero // do not keep a reference to the removed item
+	s.data = s.data[:n]
+	return item, nil
+}
+
+// Peek - return the top item without removing it. Error is returned if the stack is empty
+func (s *Stack[T]) Peek() (T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.data) == 0 {
+		var zero T
+		return zero, ErrQueueEmpty
+	}
+	return s.data[len(s.data)-1], nil
+}
+
+// Len - return the stack length
+func (s *Stack[T]) Len() int {
+	s.mu.Lock()
+	res := len(s.data)
+	s.mu.Unlock()
+	return res
+}
+
+// IsEmpty - check if the stack is empty
+func (s *Stack[T]) IsEmpty() bool {
+	return s.Len() == 0
+}
@@ -0,0 +1,83 @@
+package razutils
+
+import (
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileInfoEx - extended file metadata as returned by StatEx. fields not supported on the platform are left empty
+// (zero times, -1 ids).
+type FileInfoEx struct {
+	Path       string
+	Name       string
+	Size       int64
+	Mode       fs.FileMode
+	ModTime    time.Time
+	AccessTime time.Time
+	ChangeTime time.Time // inode/metadata change (unix)
+	CreateTime time.Time // birth time, zero if the platform/filesystem does not record it
+	UID        int
+	GID        int
+	Owner      string // user name, or the uid as string when it can not be resolved
+	Group      string // group name, or the gid as string when it can not be resolved
+	Hidden     bool   // windows hidden attribute, dot files on unix (and the hidden flag on macOS)
+	System     bool   // windows system attribute
+}
+
+// IsDir - check if the entry is a directory
+func (fi *FileInfoEx) IsDir() bool {
+	return fi.Mode.IsDir()
+}
+
+// IsHidden - check if the entry is hidden by the platform conventions
+func (fi *FileInfoEx) IsHidden() bool {
+	return fi.Hidden
+}
+
+// StatEx - return the extended metadata of a file or directory (symlinks are followed, like os.Stat)
+func StatEx(path string) (*FileInfoEx, error) {
+	info, err := os.Stat(LongPath(path))
+	if err != nil {
+		return nil, err
+	}
+	fi := &FileInfoEx{
+		Path:    path,
+		Name:    info.Name(),
+		Size:    info.Size(),
+		Mode:    info.Mode(),
+		ModTime: info.ModTime(),
+		UID:     -1,
+		GID:     -1,
+		Hidden:  strings.HasPrefix(filepath.Base(path), ".") && filepath.Base(path) != "." && filepath.Base(path) != "..",
+	}
+	if err := statExtra(path, info, fi); err != nil {
+		return nil, err
+	}
+	if fi.UID >= 0 {
+		fi.Owner = strconv.Itoa(fi.UID)
+		if u, err := user.LookupId(fi.Owner); err == nil {
+			fi.Owner = u.Username
+		}
+	}
+	if fi.GID >= 0 {
+		fi.Group = strconv.Itoa(fi.GID)
+		if g, err := user.LookupGroupId(fi.Group); err == nil {
+			fi.Group = g.Name
+		}
+	}
+	return fi, nil
+}
+
+// IsHidden - check if a file is hidden: the hidden attribute on windows, a dot name on unix.
+func IsHidden(path string) (bool, error) {
+	fi, err := StatEx(path)
+	if err != nil {
+		return false, err
+	}
+	return fi.Hidden, nil
+}
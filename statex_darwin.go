@@ -0,0 +1,26 @@
+//go:build darwin
+
+package razutils
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+// ufHidden - the UF_HIDDEN file flag (chflags hidden)
+const ufHidden = 0x8000
+
+// statExtra - fill the platform specific fields. macOS records the birth time and a hidden flag.
+func statExtra(path string, info fs.FileInfo, fi *FileInfoEx) error {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		fi.AccessTime = time.Unix(st.Atimespec.Unix())
+		fi.ChangeTime = time.Unix(st.Ctimespec.Unix())
+		fi.CreateTime = time.Unix(st.Birthtimespec.Unix())
+		fi.UID, fi.GID = int(st.Uid), int(st.Gid)
+		if st.Flags&ufHidden != 0 {
+			fi.Hidden = true
+		}
+	}
+	return nil
+}
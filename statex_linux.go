@@ -0,0 +1,25 @@
+//go:build linux
+
+package razutils
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// statExtra - fill the platform specific fields. linux returns the birth time only via statx (kernel 4.11+).
+func statExtra(path string, info fs.FileInfo, fi *FileInfoEx) error {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		fi.AccessTime = time.Unix(st.Atim.Unix())
+		fi.ChangeTime = time.Unix(st.Ctim.Unix())
+		fi.UID, fi.GID = int(st.Uid), int(st.Gid)
+	}
+	var stx unix.Statx_t
+	if err := unix.Statx(unix.AT_FDCWD, path, 0, unix.STATX_BTIME, &stx); err == nil && stx.Mask&unix.STATX_BTIME != 0 {
+		fi.CreateTime = time.Unix(stx.Btime.Sec, int64(stx.Btime.Nsec))
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !windows
+
+package razutils
+
+import "io/fs"
+
+// statExtra - no extended metadata is collected on this platform
+func statExtra(path string, info fs.FileInfo, fi *FileInfoEx) error {
+	return nil
+}
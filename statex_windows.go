@@ -0,0 +1,21 @@
+//go:build windows
+
+package razutils
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+// statExtra - fill the platform specific fields. windows has creation time and the hidden/system attributes but no
+// unix style owner ids.
+func statExtra(path string, info fs.FileInfo, fi *FileInfoEx) error {
+	if st, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		fi.AccessTime = time.Unix(0, st.LastAccessTime.Nanoseconds())
+		fi.CreateTime = time.Unix(0, st.CreationTime.Nanoseconds())
+		fi.Hidden = st.FileAttributes&syscall.FILE_ATTRIBUTE_HIDDEN != 0
+		fi.System = st.FileAttributes&syscall.FILE_ATTRIBUTE_SYSTEM != 0
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package razutils
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// SyncOptions - options for SyncDir
+type SyncOptions struct {
+	Delete  bool // delete destination files that do not exist in the source
+	UseHash bool // compare existing files by content hash (xxHash) rather than size+modification time
+	SHA256  bool // with UseHash, compare by sha256 rather than the faster xxHash
+	DryRun  bool // only return the plan, do not change anything
+}
+
+// SyncPlan - what SyncDir did (or would do in dry-run mode). paths are relative to the synced roots.
+type SyncPlan struct {
+	Copy   []string // new files copied to the destination
+	Update []string // changed files overwritten in the destination
+	Delete []string // extraneous destination files deleted
+}
+
+// SyncDir - one way sync (mirror) of directory src into dst: new and changed files are copied, unchanged ones are
+// skipped, and with opts.Delete files not in src are removed from dst.  Copied files keep the source modification
+// time so a following run sees them as unchanged.  On error the plan of what was done so far is returned.
+func SyncDir(src string, dst string, opts SyncOptions) (SyncPlan, error) {
+	var plan, done SyncPlan
+	if _, err := os.Stat(LongPath(dst)); os.IsNotExist(err) {
+		if opts.DryRun {
+			files, err := listFiles(src)
+			if err != nil {
+				return plan, err
+			}
+			for rel := range files {
+				plan.Copy = append(plan.Copy, rel)
+			}
+			sort.Strings(plan.Copy)
+			return plan, nil
+		}
+		if err := os.MkdirAll(LongPath(dst), 0755); err != nil {
+			return plan, err
+		}
+	}
+	var hash fileHash
+	switch {
+	case opts.UseHash && opts.SHA256:
+		hash = FileSHA256
+	case opts.UseHash:
+		hash = xxHashString
+	}
+	diff, err := diffTrees(src, dst, hash)
+	if err != nil {
+		return plan, err
+	}
+	plan.Copy, plan.Update = diff.Removed, diff.Changed
+	if opts.Delete {
+		plan.Delete = diff.Added
+	}
+	if opts.DryRun {
+		return plan, nil
+	}
+	for _, rel := range plan.Copy {
+		if err := syncFile(filepath.Join(src, rel), filepath.Join(dst, rel)); err != nil {
+			return done, err
+		}
+		done.Copy = append(done.Copy, rel)
+	}
+	for _, rel := range plan.Update {
+		if err := syncFile(filepath.Join(src, rel), filepath.Join(dst, rel)); err != nil {
+			return done, err
+		}
+		done.Update = append(done.Update, rel)
+	}
+	for _, rel := range plan.Delete {
+		if err := os.Remove(LongPath(filepath.Join(dst, rel))); err != nil {
+			return done, err
+		}
+		done.Delete = append(done.Delete, rel)
+	}
+	return done, nil
+}
+
+// syncFile - copy a single file creating the destination directory, and set the source modification time on it
+func syncFile(src string, dst string) error {
+	info, err := os.Stat(LongPath(src))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(LongPath(filepath.Dir(dst)), 0755); err != nil {
+		return err
+	}
+	if err := CopyFile(src, dst); err != nil {
+		return err
+	}
+	return os.Chtimes(LongPath(dst), info.ModTime(), info.ModTime())
+}
@@ -0,0 +1,28 @@
+package razutils
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// TestSyncDirDryRunSorted - the dry run plan for a missing destination is sorted, as the DiffTrees based one
+func TestSyncDirDryRunSorted(t *testing.T) {
+	d := t.TempDir()
+	src := filepath.Join(d, "src")
+	for _, n := range []string{"c", "a", "b/x", "b/a", "e", "d"} {
+		p := filepath.Join(src, n)
+		os.MkdirAll(filepath.Dir(p), 0o755)
+		if err := os.WriteFile(p, []byte(n), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	plan, err := SyncDir(src, filepath.Join(d, "missing"), SyncOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Copy) != 6 || !sort.StringsAreSorted(plan.Copy) {
+		t.Fatalf("plan not sorted: %v", plan.Copy)
+	}
+}
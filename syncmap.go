@@ -0,0 +1,128 @@
+package razutils
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+/*
+SyncMap - a typed thread safe map.  Keys are spread over shards, each with its own RWMutex, so goroutines writing
+different keys rarely contend.
+*/
+
+type syncMapShard[K comparable, V any] struct {
+	items map[K]V
+	mu    sync.RWMutex
+}
+
+type SyncMap[K comparable, V any] struct {
+	shards []syncMapShard[K, V]
+	seed   maphash.Seed
+}
+
+// NewSyncMap - create a map with the given number of shards (0 or less means 32).  a single shard makes it a plain
+// RWMutex protected map.
+func NewSyncMap[K comparable, V any](shards int) *SyncMap[K, V] {
+	if shards <= 0 {
+		shards = 32
+	}
+	m := &SyncMap[K, V]{shards: make([]syncMapShard[K, V], shards), seed: maphash.MakeSeed()}
+	for i := range m.shards {
+		m.shards[i].items = make(map[K]V)
+	}
+	return m
+}
+
+func (m *SyncMap[K, V]) shard(key K) *syncMapShard[K, V] {
+	if len(m.shards) == 1 {
+		return &m.shards[0]
+	}
+	return &m.shards[maphash.Comparable(m.seed, key)%uint64(len(m.shards))]
+}
+
+// Load - return the value of key
+func (m *SyncMap[K, V]) Load(key K) (V, bool) {
+	s := m.shard(key)
+	s.mu.RLock()
+	v, ok := s.items[key]
+	s.mu.RUnlock()
+	return v, ok
+}
+
+// Store - set the value of key
+func (m *SyncMap[K, V]) Store(key K, value V) {
+	s := m.shard(key)
+	s.mu.Lock()
+	s.items[key] = value
+	s.mu.Unlock()
+}
+
+// Delete - remove key
+func (m *SyncMap[K, V]) Delete(key K) {
+	s := m.shard(key)
+	s.mu.Lock()
+	delete(s.items, key)
+	s.mu.Unlock()
+}
+
+// LoadAndDelete - remove key returning its value
+func (m *SyncMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	s := m.shard(key)
+	s.mu.Lock()
+	v, ok := s.items[key]
+	delete(s.items, key)
+	s.mu.Unlock()
+	return v, ok
+}
+
+// GetOrCreate - return the value of key, creating it with fn if it is missing.  fn is called at most once per key
+// (under the shard lock, so it must not use the map).  loaded reports if the value already existed.
+func (m *SyncMap[K, V]) GetOrCreate(key K, fn func() V) (value V, loaded bool) {
+	s := m.shard(key)
+	s.mu.RLock()
+	v, ok := s.items[key]
+	s.mu.RUnlock()
+	if ok {
+		return v, true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok := s.items[key]; ok {
+		return v, true
+	}
+	v = fn()
+	s.items[key] = v
+	return v, false
+}
+
+// Range - call fn for each key/value until it returns false. each shard is copied under its lock and fn is called
+// outside it, so fn may use the map (changes may or may not be seen by the iteration).
+func (m *SyncMap[K, V]) Range(fn func(K, V) bool) {
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.RLock()
+		keys := make([]K, 0, len(s.items))
+		vals := make([]V, 0, len(s.items))
+		for k, v := range s.items {
+			keys = append(keys, k)
+			vals = append(vals, v)
+		}
+		s.mu.RUnlock()
+		for j := range keys {
+			if !fn(keys[j], vals[j]) {
+				return
+			}
+		}
+	}
+}
+
+// Len - return the number of keys
+func (m *SyncMap[K, V]) Len() int {
+	n := 0
+	for i := range m.shards {
+		m.shards[i].mu.RLock()
+		n += len(m.shards[i].items)
+		m.shards[i].mu.RUnlock()
+	}
+	return n
+}
@@ -0,0 +1,76 @@
+package razutils
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// tailPoll - how often TailFile checks for new data once it reached the end of the file
+const tailPoll = 250 * time.Millisecond
+
+// TailFile - follow a file like "tail -F": lines appended to the file are sent on the returned channel (without the
+// line ending).  Reading starts at the current end of the file.  When the file is truncated it is read again from the
+// start, and when it is renamed/replaced (log rotation) the new file is opened once it appears.
+// The channel is closed when the context is done.
+func TailFile(ctx context.Context, path string) (<-chan string, error) {
+	f, err := os.Open(LongPath(path))
+	if err != nil {
+		return nil, err
+	}
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	out := make(chan string)
+	go func() {
+		defer close(out)
+		defer func() { f.Close() }()
+		r := bufio.NewReader(f)
+		partial := ""
+		for {
+			line, err := r.ReadString('\n')
+			offset += int64(len(line))
+			if err == nil {
+				select {
+				case out <- strings.TrimRight(partial+line, "\r\n"):
+				case <-ctx.Done():
+					return
+				}
+				partial = ""
+				continue
+			}
+			partial += line // incomplete line, wait for the rest of it
+			if err != io.EOF {
+				return
+			}
+			select {
+			case <-time.After(tailPoll):
+			case <-ctx.Done():
+				return
+			}
+			cur, err1 := f.Stat()
+			st, err2 := os.Stat(LongPath(path))
+			switch {
+			case err1 == nil && err2 == nil && !os.SameFile(cur, st):
+				// rotated: the data left in the old file was already read, switch to the new one
+				if nf, err := os.Open(LongPath(path)); err == nil {
+					f.Close()
+					f, offset, partial = nf, 0, ""
+					r.Reset(f)
+				}
+			case err1 == nil && cur.Size() < offset:
+				// truncated in place
+				if _, err := f.Seek(0, io.SeekStart); err == nil {
+					offset, partial = 0, ""
+					r.Reset(f)
+				}
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,358 @@
+package razutils
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TarCreate - create a tar at dest holding the given files and directories (recursively, each under its base name),
+// keeping modes, modification times, directory entries and symlinks.  A dest ending with .gz or .tgz is gzipped.
+func TarCreate(dest string, paths []string, opts ...ArchiveOption) error {
+	cfg := archiveOptions(opts)
+	files, err := collectArchiveFiles(paths)
+	if err != nil {
+		return err
+	}
+	return tarWrite(dest, files, cfg, nil)
+}
+
+// TarCreateFiltered - create a tar at dest (compressed by its extension as for TarCreate) of the regular files under
+// root kept by the glob patterns, e.g. only the subtitles and infos with []string{"*.srt", "*.nfo"}.  The patterns
+// are matched case ignored against the path relative to root, with the WithMatch rules: a pattern with no "/" is
+// matched against the base name.  An empty includeGlobs keeps all the files.  A file is dropped if an exclude
+// pattern matches it or one of its directories (e.g. ".git", "sample" or "old/*").  As with TarCreate the files
+// are stored under the root base name, directories are not stored on their own.
+func TarCreateFiltered(dest string, root string, includeGlobs []string, excludeGlobs []string, opts ...ArchiveOption) error {
+	cfg := archiveOptions(opts)
+	root = filepath.Clean(root)
+	base := filepath.Dir(root)
+	destAbs := absClean(dest)
+	var files []archiveFile
+	for p, err := range WalkFiles(context.Background(), root, nil) {
+		if err != nil {
+			return err
+		}
+		if isArchiveOrPart(p, destAbs) {
+			continue
+		}
+		rel, err := filepath.Rel(LongPath(root), p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if (len(includeGlobs) > 0 && !matchGlobs(includeGlobs, rel)) || excludedPath(excludeGlobs, rel) {
+			continue
+		}
+		info, err := os.Lstat(p)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(LongPath(base), p)
+		if err != nil {
+			return err
+		}
+		files = append(files, archiveFile{path: p, name: filepath.ToSlash(name), info: info})
+	}
+	return tarWrite(dest, files, cfg, nil)
+}
+
+// excludedPath - check if one of the patterns matches the slash separated rel or one of its parent directories
+func excludedPath(patterns []string, rel string) bool {
+	parts := strings.Split(rel, "/")
+	for i := range parts {
+		if matchGlobs(patterns, strings.Join(parts[:i+1], "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// tarWrite - write the files into a new tar at dest, after a pax global header with the given records if there are
+// any (the package own metadata, it can not collide with a file entry)
+func tarWrite(dest string, files []archiveFile, cfg archiveConfig, global map[string]string) error {
+	fout, err := createArchiveFile(dest, cfg)
+	if err != nil {
+		return err
+	}
+	defer fout.Close()
+	cw, err := compressWriterFor(dest, fout, cfg.level)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(cw)
+	if len(global) > 0 {
+		if err = tw.WriteHeader(&tar.Header{Typeflag: tar.TypeXGlobalHeader, PAXRecords: global}); err != nil {
+			return err
+		}
+	}
+	for _, f := range files {
+		if err = tarAddFile(tw, f); err != nil {
+			return err
+		}
+	}
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	if err = cw.Close(); err != nil {
+		return err
+	}
+	return fout.Close()
+}
+
+// tarAddFile - add a single file, directory or symlink entry
+func tarAddFile(tw *tar.Writer, f archiveFile) error {
+	link := ""
+	if f.info.Mode()&fs.ModeSymlink != 0 {
+		var err error
+		if link, err = os.Readlink(f.path); err != nil {
+			return err
+		}
+	}
+	hdr, err := tar.FileInfoHeader(f.info, link)
+	if err != nil {
+		return err
+	}
+	hdr.Name = f.name
+	if f.info.IsDir() {
+		hdr.Name += "/"
+	}
+	if err = tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if !f.info.Mode().IsRegular() {
+		return nil
+	}
+	r, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	_, err = io.Copy(tw, pausable(r))
+	return err
+}
+
+// TarExtract - extract the tar src into destDir, restoring modes, modification times, directories and links.  The
+// compression is detected by the content, not the name.  Entries, and link targets, that would go out of destDir
+// (also through symlinks extracted by earlier entries, which are followed on disk) fail the extraction with
+// ErrUnsafePath (see WithLenientPaths).  An entry replaces a symlink at its path, it is never written through it.
+func TarExtract(src string, destDir string, opts ...ArchiveOption) error {
+	fin, err := openArchive(src)
+	if err != nil {
+		return err
+	}
+	defer fin.Close()
+	cfg := archiveOptions(opts)
+	cfg.progress.setTotal(fin.Size())
+	r, err := NewAutoDecompressor(cfg.progress.reader(fin))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return tarExtractStream(r, destDir, &cfg)
+}
+
+// tarExtractStream - extract the (uncompressed) tar stream r into destDir
+func tarExtractStream(r io.Reader, destDir string, cfg *archiveConfig) error {
+	// the real destDir, the links extracted are resolved against it
+	if err := os.MkdirAll(LongPath(destDir), 0o755); err != nil {
+		return err
+	}
+	root, err := filepath.EvalSymlinks(destDir)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(r)
+	dt := dirTimes{}
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag == tar.TypeXGlobalHeader {
+			if cfg.paxGlobal != nil {
+				cfg.paxGlobal(hdr.PAXRecords)
+			}
+			continue
+		}
+		if !cfg.wants(hdr.Name, hdr.Typeflag == tar.TypeReg) {
+			continue
+		}
+		target, err := cfg.target(destDir, hdr.Name)
+		if err != nil {
+			return err
+		}
+		realDir := root
+		if target != destDir {
+			realDir, err = tarRealParent(root, target)
+			if errors.Is(err, ErrUnsafePath) && cfg.lenient {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		// an entry replaces a symlink extracted before, it is not written through it
+		if info, err := os.Lstat(LongPath(target)); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+			if err = os.Remove(LongPath(target)); err != nil {
+				return err
+			}
+		}
+		cfg.progress.entry(hdr.Name)
+		mode := hdr.FileInfo().Mode()
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(LongPath(target), 0o755); err != nil {
+				return err
+			}
+			if err = os.Chmod(LongPath(target), mode.Perm()|0o700); err != nil {
+				return err
+			}
+			dt[target] = hdr.ModTime
+		case tar.TypeReg:
+			if err = writeEntryFile(target, tr, mode, hdr.ModTime); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			err = tarSymlink(root, realDir, target, hdr.Linkname)
+			if errors.Is(err, ErrUnsafePath) && cfg.lenient {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			old, err := entryPath(destDir, hdr.Linkname, cfg.lenient)
+			if err != nil {
+				return err
+			}
+			if _, err = tarRealParent(root, old); err != nil {
+				return err
+			}
+			_ = os.Remove(LongPath(target))
+			if err = os.Link(LongPath(old), LongPath(target)); err != nil {
+				return err
+			}
+		default:
+			// devices, fifos etc. are skipped
+		}
+	}
+	return dt.apply()
+}
+
+// tarSymlink - create a symlink, refusing targets that point out of root (the real destDir).  realDir is the real
+// directory of target, the link target is resolved from there on disk (see tarLinkTarget).  A link does not replace
+// a directory, as a link under it may already have been resolved through it.
+func tarSymlink(root, realDir, target, linkname string) error {
+	if _, err := tarLinkTarget(root, realDir, linkname); err != nil {
+		return fmt.Errorf("link %s -> %s: %w", target, linkname, err)
+	}
+	if info, err := os.Lstat(LongPath(target)); err == nil && info.IsDir() {
+		return fmt.Errorf("%w: link %s replaces a directory", ErrUnsafePath, target)
+	}
+	if err := os.MkdirAll(LongPath(filepath.Dir(target)), 0o755); err != nil {
+		return err
+	}
+	_ = os.Remove(LongPath(target))
+	return os.Symlink(linkname, target)
+}
+
+// tarMaxLinks - the most symlinks followed resolving a link target, as the system limit (a loop is refused)
+const tarMaxLinks = 40
+
+// tarLinkTarget - resolve linkname from realDir on disk, a component at a time following the symlinks extracted by
+// earlier entries (so ".." is taken from where a link really points), and check that the result is inside root.  A
+// ".." after a component that does not exist yet is refused, that component could be extracted later as a symlink.
+func tarLinkTarget(root, realDir, linkname string) (string, error) {
+	cur := realDir
+	if filepath.IsAbs(linkname) {
+		cur = filepath.VolumeName(linkname) + string(filepath.Separator)
+	}
+	parts := linkParts(linkname)
+	missing := false
+	for links := 0; len(parts) > 0; {
+		part := parts[0]
+		parts = parts[1:]
+		switch {
+		case part == "" || part == ".":
+			continue
+		case part == ".." && missing:
+			return "", fmt.Errorf("%w: .. after a missing directory", ErrUnsafePath)
+		case part == "..":
+			cur = filepath.Dir(cur)
+			continue
+		}
+		cur = filepath.Join(cur, part)
+		if missing {
+			continue
+		}
+		info, err := os.Lstat(LongPath(cur))
+		if errors.Is(err, fs.ErrNotExist) {
+			missing = true
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&fs.ModeSymlink == 0 {
+			continue
+		}
+		if links++; links > tarMaxLinks {
+			return "", fmt.Errorf("%w: too many links", ErrUnsafePath)
+		}
+		dest, err := os.Readlink(LongPath(cur))
+		if err != nil {
+			return "", err
+		}
+		cur = filepath.Dir(cur)
+		if filepath.IsAbs(dest) {
+			cur = filepath.VolumeName(dest) + string(filepath.Separator)
+		}
+		parts = append(linkParts(dest), parts...)
+	}
+	if !IsSubPath(root, cur) {
+		return "", fmt.Errorf("%w: %s is out of %s", ErrUnsafePath, cur, root)
+	}
+	return cur, nil
+}
+
+// linkParts - the path components of a link target, without its volume
+func linkParts(name string) []string {
+	return strings.Split(filepath.ToSlash(name[len(filepath.VolumeName(name)):]), "/")
+}
+
+// tarRealParent - the real directory of target, following the symlinks on disk (extracted by earlier entries), and
+// checked to be inside root.  The directories not created yet are taken as they are named, they will be created as
+// real directories.
+func tarRealParent(root, target string) (string, error) {
+	dir := filepath.Dir(target)
+	var missing []string
+	for {
+		real, err := filepath.EvalSymlinks(dir)
+		if err == nil {
+			real = filepath.Join(append([]string{real}, missing...)...)
+			if !IsSubPath(root, real) {
+				return "", fmt.Errorf("%w: %s", ErrUnsafePath, target)
+			}
+			return real, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		up := filepath.Dir(dir)
+		if up == dir {
+			return "", err
+		}
+		missing = append([]string{filepath.Base(dir)}, missing...)
+		dir = up
+	}
+}
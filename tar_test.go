@@ -0,0 +1,111 @@
+package razutils
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// tarTestEntry - an entry of a tar built by writeTestTar, a symlink when link is set
+type tarTestEntry struct {
+	name, link, data string
+}
+
+// writeTestTar - write a tar with the given entries at path
+func writeTestTar(t *testing.T, path string, entries ...tarTestEntry) {
+	t.Helper()
+	var b bytes.Buffer
+	tw := tar.NewWriter(&b)
+	for _, e := range entries {
+		if e.link != "" {
+			tw.WriteHeader(&tar.Header{Name: e.name, Typeflag: tar.TypeSymlink, Linkname: e.link, Mode: 0o777})
+			continue
+		}
+		tw.WriteHeader(&tar.Header{Name: e.name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(e.data))})
+		tw.Write([]byte(e.data))
+	}
+	tw.Close()
+	if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestTarExtractSymlinkEscape - a file written under a symlink chain ("d -> .", "d/e -> ..") must not land outside
+// the destination
+func TestTarExtractSymlinkEscape(t *testing.T) {
+	base := t.TempDir()
+	src := filepath.Join(base, "evil.tar")
+	writeTestTar(t, src, tarTestEntry{name: "d", link: "."}, tarTestEntry{name: "d/e", link: ".."},
+		tarTestEntry{name: "e/pwned", data: "owned"})
+	dest := filepath.Join(base, "out")
+	err := TarExtract(src, dest)
+	if !errors.Is(err, ErrUnsafePath) {
+		t.Fatalf("expected ErrUnsafePath, got %v", err)
+	}
+	if _, err = os.Lstat(filepath.Join(base, "pwned")); err == nil {
+		t.Fatal("file written outside the destination")
+	}
+}
+
+// TestTarExtractLinkThroughLink - a link target with ".." is resolved through the links extracted before it, not
+// collapsed as text
+func TestTarExtractLinkThroughLink(t *testing.T) {
+	base := t.TempDir()
+	for _, entries := range [][]tarTestEntry{
+		{{name: "sub/d", link: "."}, {name: "sub/l", link: "d/../.."}},
+		{{name: "sub/d", link: "."}, {name: "sub/l", link: "d/../../x"}},
+		// "m" does not exist when "l" is made, it could be made a link to "." after it
+		{{name: "l", link: "m/.."}, {name: "m", link: "."}},
+		// a directory "l" has been resolved through is not replaced by a link
+		{{name: "d/f", data: "x"}, {name: "l", link: "d/.."}, {name: "d", link: "."}},
+	} {
+		src := filepath.Join(base, "evil.tar")
+		writeTestTar(t, src, entries...)
+		dest := filepath.Join(base, "out")
+		os.RemoveAll(dest)
+		if err := TarExtract(src, dest); !errors.Is(err, ErrUnsafePath) {
+			t.Fatalf("%v: expected ErrUnsafePath, got %v", entries, err)
+		}
+	}
+}
+
+// TestTarExtractOverSymlink - a link out of the destination through an extracted link is refused (skipped when
+// lenient), and a regular entry replaces a symlink extracted before instead of writing through it
+func TestTarExtractOverSymlink(t *testing.T) {
+	base := t.TempDir()
+	outside := filepath.Join(base, "outside.txt")
+	os.WriteFile(outside, []byte("keep"), 0o644)
+	src := filepath.Join(base, "evil.tar")
+	writeTestTar(t, src, tarTestEntry{name: "d", link: "."}, tarTestEntry{name: "x", link: "d/../outside.txt"},
+		tarTestEntry{name: "x", data: "new"})
+	dest := filepath.Join(base, "out")
+	if err := TarExtract(src, dest); !errors.Is(err, ErrUnsafePath) {
+		t.Fatalf("expected ErrUnsafePath, got %v", err)
+	}
+	os.RemoveAll(dest)
+	if err := TarExtract(src, dest, WithLenientPaths()); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(outside); string(data) != "keep" {
+		t.Fatalf("outside file changed to %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dest, "x")); string(data) != "new" {
+		t.Fatalf("entry content %q", data)
+	}
+	// a link inside the destination, replaced by a regular entry
+	writeTestTar(t, src, tarTestEntry{name: "d", link: "."}, tarTestEntry{name: "f", data: "f"},
+		tarTestEntry{name: "x", link: "d/f"}, tarTestEntry{name: "x", data: "new"})
+	os.RemoveAll(dest)
+	if err := TarExtract(src, dest); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dest, "f")); string(data) != "f" {
+		t.Fatalf("link target changed to %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dest, "x")); string(data) != "new" {
+		t.Fatalf("entry content %q", data)
+	}
+}
@@ -0,0 +1,78 @@
+package razutils
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"time"
+)
+
+/*
+Runtime toggles - allow a long-running program to be diagnosed without a restart.
+After EnableRuntimeToggles is called:
+  - on unix, SIGUSR1 flips the log verbosity (LogLevel between Info and Debug) and SIGUSR2 dumps the goroutine
+    and heap profiles into files in the profile directory.
+  - on windows, where those signals do not exist, a named pipe \\.\pipe\razutils-<pid> is opened, writing
+    "verbose" or "profile" into it does the same.
+Programs should build their slog handler with LogLevel (slog.HandlerOptions{Level: LogLevel}) or check Verbose().
+*/
+
+// LogLevel - the package log level, flipped by the runtime toggles.
+var LogLevel = new(slog.LevelVar)
+
+// Verbose - return true if debug level logging is currently on.
+func Verbose() bool {
+	return LogLevel.Level() <= slog.LevelDebug
+}
+
+// toggleVerbose - flip between Info and Debug levels
+func toggleVerbose() {
+	if Verbose() {
+		LogLevel.Set(slog.LevelInfo)
+		log.Println("runtime toggle: verbose logging off")
+	} else {
+		LogLevel.Set(slog.LevelDebug)
+		log.Println("runtime toggle: verbose logging on")
+	}
+}
+
+// DumpProfiles - write the goroutine (text, with full stacks) and heap profiles into dir.  returns the created files.
+func DumpProfiles(dir string) ([]string, error) {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	base := filepath.Join(dir, fmt.Sprintf("razutils-%d-%s", os.Getpid(), time.Now().Format("20060102-150405")))
+	var files []string
+	for _, p := range []struct {
+		name  string
+		file  string
+		debug int
+	}{{"goroutine", base + "-goroutine.txt", 2}, {"heap", base + "-heap.pprof", 0}} {
+		f, err := os.Create(p.file)
+		if err != nil {
+			return files, err
+		}
+		err = pprof.Lookup(p.name).WriteTo(f, p.debug)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return files, err
+		}
+		files = append(files, p.file)
+	}
+	return files, nil
+}
+
+// dumpProfilesLogged - dump the profiles and log the outcome, used by the toggles
+func dumpProfilesLogged(dir string) {
+	files, err := DumpProfiles(dir)
+	if err != nil {
+		log.Println("runtime toggle: profile dump failed:", err)
+		return
+	}
+	log.Println("runtime toggle: profiles written to", files)
+}
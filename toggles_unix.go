@@ -0,0 +1,35 @@
+//go:build unix
+
+package razutils
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// EnableRuntimeToggles - start listening on SIGUSR1 (toggle verbose logging) and SIGUSR2 (dump profiles into
+// profileDir, empty means the temp dir).  The returned func stops the listening.
+func EnableRuntimeToggles(profileDir string) (func(), error) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		for {
+			select {
+			case s := <-ch:
+				if s == syscall.SIGUSR1 {
+					toggleVerbose()
+				} else {
+					dumpProfilesLogged(profileDir)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}, nil
+}
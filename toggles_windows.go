@@ -0,0 +1,50 @@
+//go:build windows
+
+package razutils
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/Microsoft/go-winio"
+)
+
+// EnableRuntimeToggles - open the named pipe \\.\pipe\razutils-<pid> and act on the commands written into it:
+// "verbose" toggles verbose logging, "profile" dumps profiles into profileDir (empty means the temp dir).
+// The returned func closes the pipe.
+func EnableRuntimeToggles(profileDir string) (func(), error) {
+	l, err := winio.ListenPipe(fmt.Sprintf(`\\.\pipe\razutils-%d`, os.Getpid()), nil)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return // listener closed
+			}
+			go handleToggleConn(conn, profileDir)
+		}
+	}()
+	return func() { l.Close() }, nil
+}
+
+func handleToggleConn(conn net.Conn, profileDir string) {
+	defer conn.Close()
+	sc := bufio.NewScanner(conn)
+	for sc.Scan() {
+		switch strings.ToLower(strings.TrimSpace(sc.Text())) {
+		case "verbose":
+			toggleVerbose()
+		case "profile":
+			dumpProfilesLogged(profileDir)
+		case "":
+		default:
+			log.Println("runtime toggle: unknown command", sc.Text())
+		}
+	}
+}
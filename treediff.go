@@ -0,0 +1,125 @@
+package razutils
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// mtimeSlack - modification times closer than this are considered equal (FAT and some network shares store
+// times with a 2 seconds resolution)
+const mtimeSlack = 2 * time.Second
+
+// TreeDiff - the result of DiffTrees, all paths are relative to the compared roots and sorted
+type TreeDiff struct {
+	Added   []string // in b only
+	Removed []string // in a only
+	Changed []string // in both but different
+}
+
+// IsEmpty - check if no differences were found
+func (d TreeDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// listFiles - return all the regular files under root, by their path relative to root
+func listFiles(root string) (map[string]fs.FileInfo, error) {
+	files := make(map[string]fs.FileInfo)
+	root = LongPath(root)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files[rel] = info
+		return nil
+	})
+	return files, err
+}
+
+// fileHash - a content hash of a file used to compare files, as a comparable string
+type fileHash func(path string) (string, error)
+
+// xxHashString - the xxHash64 of a file as a string, the fast hash for change detection
+func xxHashString(path string) (string, error) {
+	h, err := FileXXHash64(path)
+	return strconv.FormatUint(h, 16), err
+}
+
+// sameFile - check if the two files are the same, by size and modification time or (hash not nil) by size and
+// content hash
+func sameFile(pathA string, infoA fs.FileInfo, pathB string, infoB fs.FileInfo, hash fileHash) (bool, error) {
+	if infoA.Size() != infoB.Size() {
+		return false, nil
+	}
+	if hash == nil {
+		return infoA.ModTime().Sub(infoB.ModTime()).Abs() < mtimeSlack, nil
+	}
+	ha, err := hash(pathA)
+	if err != nil {
+		return false, err
+	}
+	hb, err := hash(pathB)
+	if err != nil {
+		return false, err
+	}
+	return ha == hb, nil
+}
+
+// DiffTrees - compare the files in directory tree a to tree b (e.g. a source and its mirror copy).  Files are
+// considered changed when size or modification time differ, or with useHash when size or content (by xxHash)
+// differ.
+func DiffTrees(a string, b string, useHash bool) (TreeDiff, error) {
+	if useHash {
+		return diffTrees(a, b, xxHashString)
+	}
+	return diffTrees(a, b, nil)
+}
+
+// diffTrees - DiffTrees comparing the content with hash (nil for size and modification time)
+func diffTrees(a string, b string, hash fileHash) (TreeDiff, error) {
+	var diff TreeDiff
+	filesA, err := listFiles(a)
+	if err != nil {
+		return diff, err
+	}
+	filesB, err := listFiles(b)
+	if err != nil {
+		return diff, err
+	}
+	for rel, infoA := range filesA {
+		infoB, ok := filesB[rel]
+		if !ok {
+			diff.Removed = append(diff.Removed, rel)
+			continue
+		}
+		same, err := sameFile(filepath.Join(a, rel), infoA, filepath.Join(b, rel), infoB, hash)
+		if err != nil {
+			return diff, err
+		}
+		if !same {
+			diff.Changed = append(diff.Changed, rel)
+		}
+	}
+	for rel := range filesB {
+		if _, ok := filesA[rel]; !ok {
+			diff.Added = append(diff.Added, rel)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	return diff, nil
+}
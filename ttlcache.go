@@ -0,0 +1,175 @@
+package razutils
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+/*
+TTLCache - a thread safe cache whose entries expire a fixed duration after being set.  A janitor goroutine removes
+the expired entries periodically (expired entries are never returned, even before the janitor removes them).
+GetOrCompute computes missing values once per key even when called concurrently.  Close stops the janitor.
+*/
+
+type ttlEntry[V any] struct {
+	value V
+	exp   time.Time
+}
+
+// ttlCall - a GetOrCompute computation in progress
+type ttlCall[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+type TTLCache[K comparable, V any] struct {
+	ttl      time.Duration
+	items    map[K]ttlEntry[V]
+	inflight map[K]*ttlCall[V]
+	mu       sync.Mutex
+	stop     chan struct{}
+	stopped  chan struct{}
+	once     sync.Once
+}
+
+// ttlDefaultCleanup - the janitor interval when neither cleanup nor ttl is positive
+const ttlDefaultCleanup = time.Minute
+
+// NewTTLCache - create a cache with entries living for ttl, expired entries are removed every cleanup interval
+// (0 means ttl, or a minute if ttl is not positive either).  Close must be called to stop the janitor goroutine.
+func NewTTLCache[K comparable, V any](ttl time.Duration, cleanup time.Duration) *TTLCache[K, V] {
+	if cleanup <= 0 {
+		cleanup = ttl
+	}
+	if cleanup <= 0 {
+		cleanup = ttlDefaultCleanup
+	}
+	c := &TTLCache[K, V]{
+		ttl:      ttl,
+		items:    make(map[K]ttlEntry[V]),
+		inflight: make(map[K]*ttlCall[V]),
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go c.janitor(cleanup)
+	return c
+}
+
+func (c *TTLCache[K, V]) janitor(interval time.Duration) {
+	defer close(c.stopped)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			c.DeleteExpired()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// Get - return the value of key if it is set and not expired
+func (c *TTLCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.getLocked(key)
+}
+
+func (c *TTLCache[K, V]) getLocked(key K) (V, bool) {
+	e, ok := c.items[key]
+	if !ok || !time.Now().Before(e.exp) {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Set - set the value of key with the cache ttl
+func (c *TTLCache[K, V]) Set(key K, value V) {
+	c.SetTTL(key, value, c.ttl)
+}
+
+// SetTTL - set the value of key with a specific ttl
+func (c *TTLCache[K, V]) SetTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	c.items[key] = ttlEntry[V]{value: value, exp: time.Now().Add(ttl)}
+	c.mu.Unlock()
+}
+
+// Delete - remove key from the cache
+func (c *TTLCache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	delete(c.items, key)
+	c.mu.Unlock()
+}
+
+// DeleteExpired - remove all expired entries, returning how many were removed. it is called by the janitor.
+func (c *TTLCache[K, V]) DeleteExpired() int {
+	now := time.Now()
+	cnt := 0
+	c.mu.Lock()
+	for k, e := range c.items {
+		if !now.Before(e.exp) {
+			delete(c.items, k)
+			cnt++
+		}
+	}
+	c.mu.Unlock()
+	return cnt
+}
+
+// Len - return the number of entries (including expired ones not removed yet)
+func (c *TTLCache[K, V]) Len() int {
+	c.mu.Lock()
+	res := len(c.items)
+	c.mu.Unlock()
+	return res
+}
+
+// GetOrCompute - return the cached value of key, or compute it with fn and cache it.  Concurrent calls for the same
+// key wait for a single computation.  Errors are returned to all the waiting callers and are not cached, a panic in fn
+// is returned to them as a *PanicError.
+func (c *TTLCache[K, V]) GetOrCompute(key K, fn func() (V, error)) (V, error) {
+	c.mu.Lock()
+	if v, ok := c.getLocked(key); ok {
+		c.mu.Unlock()
+		return v, nil
+	}
+	if call, ok := c.inflight[key]; ok {
+		c.mu.Unlock()
+		<-call.done
+		return call.value, call.err
+	}
+	call := &ttlCall[V]{done: make(chan struct{})}
+	c.inflight[key] = call
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.inflight, key)
+		if call.err == nil {
+			c.items[key] = ttlEntry[V]{value: call.value, exp: time.Now().Add(c.ttl)}
+		}
+		c.mu.Unlock()
+		close(call.done)
+	}()
+	call.err = safeRun(func() (err error) {
+		call.value, err = fn()
+		return err
+	})
+	return call.value, call.err
+}
+
+// Close - stop the janitor goroutine, waiting for it to end or for the context to be done
+func (c *TTLCache[K, V]) Close(ctx context.Context) error {
+	c.once.Do(func() { close(c.stop) })
+	select {
+	case <-c.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
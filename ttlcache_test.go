@@ -0,0 +1,39 @@
+package razutils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestTTLCacheComputePanic - a panic in the compute function is returned as an error and does not block the key
+func TestTTLCacheComputePanic(t *testing.T) {
+	c := NewTTLCache[string, int](time.Minute, 0)
+	defer c.Close(context.Background())
+	_, err := c.GetOrCompute("k", func() (int, error) { panic("boom") })
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected a PanicError, got %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if v, err := c.GetOrCompute("k", func() (int, error) { return 7, nil }); v != 7 || err != nil {
+			t.Errorf("got %d %v", v, err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("GetOrCompute blocked after a panic")
+	}
+}
+
+// TestTTLCacheZeroTTL - a cache with no positive ttl or cleanup interval is created without panicking
+func TestTTLCacheZeroTTL(t *testing.T) {
+	c := NewTTLCache[string, int](0, 0)
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
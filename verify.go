@@ -0,0 +1,187 @@
+package razutils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/nwaples/rardecode/v2"
+)
+
+/*
+Verification of archives, e.g. of backups before the originals are removed: everything is read and checked, nothing
+is written to disk.
+*/
+
+// EntryReport - the verification result of an archive file entry
+type EntryReport struct {
+	Name string
+	Size int64 // uncompressed bytes read
+	Err  error // nil if the entry is fine
+}
+
+// ArchiveReport - the result of VerifyArchive: the file entries checked and a problem with the archive as a whole
+// (broken structure, truncation) that stopped the check
+type ArchiveReport struct {
+	Path    string
+	Type    FileType
+	Entries []EntryReport
+	Err     error
+}
+
+// OK - check if the archive and all its entries are fine
+func (r *ArchiveReport) OK() bool {
+	return r.problems() == nil
+}
+
+// problems - the archive error and the entry errors (as FileError) joined
+func (r *ArchiveReport) problems() error {
+	errs := []error{r.Err}
+	for _, e := range r.Entries {
+		if e.Err != nil {
+			errs = append(errs, FileError{Path: e.Name, Err: e.Err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *ArchiveReport) add(name string, size int64, err error) {
+	r.Entries = append(r.Entries, EntryReport{Name: name, Size: size, Err: err})
+}
+
+// VerifyArchive - check the archive at path without writing anything: every file entry is read completely and its
+// checksum checked (the CRCs of zip, rar and gzip, the checks of zstd, xz and bzip2) and the tar structure is read to
+// its end of archive marker (a tar cut off without it is an io.ErrUnexpectedEOF).  The report lists the file entries
+// (directories and links are not listed), the returned error joins all the problems found (nil when the archive is
+// fine).  An error with a nil report means the archive could not be checked at all (missing, or
+// ErrUnsupportedArchive).  WithPassword is used for encrypted zips and rars, split archives are verified as for
+// ExtractArchive.
+func VerifyArchive(path string, opts ...ArchiveOption) (*ArchiveReport, error) {
+	cfg := archiveOptions(opts)
+	ar, err := openArchive(path)
+	if err != nil {
+		return nil, err
+	}
+	defer ar.Close()
+	h := make([]byte, sniffLen)
+	n, _ := ar.ReadAt(h, 0)
+	rep := &ArchiveReport{Path: path, Type: detectType(h[:n])}
+	switch rep.Type {
+	case FileTypeZip:
+		rep.Err = verifyZip(rep, ar, &cfg)
+	case FileTypeTar, FileTypeGzip, FileTypeZstd, FileTypeBzip2, FileTypeXz:
+		rep.Err = verifyStream(rep, ar)
+	case FileTypeRar:
+		rep.Err = verifyRar(rep, path, &cfg)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedArchive, path)
+	}
+	return rep, rep.problems()
+}
+
+// readEntry - read r to its end, adding the result to the report
+func (r *ArchiveReport) readEntry(name string, rd io.Reader) error {
+	n, err := io.Copy(io.Discard, pausable(rd))
+	r.add(name, n, err)
+	return err
+}
+
+func verifyZip(rep *ArchiveReport, ar *archiveReader, cfg *archiveConfig) error {
+	zr, err := zip.NewReader(ar, ar.Size())
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		r, err := zipOpen(f, cfg.password)
+		if err != nil {
+			rep.add(f.Name, 0, err)
+			continue
+		}
+		// the entry reader checks the CRC (and the AES authentication) at its end
+		_ = rep.readEntry(f.Name, r)
+		r.Close()
+	}
+	return nil
+}
+
+// verifyStream - a tar, compressed or not, or a single compressed file
+func verifyStream(rep *ArchiveReport, ar *archiveReader) error {
+	r, err := NewAutoDecompressor(ar)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	br := bufio.NewReaderSize(r, sniffLen)
+	if h, _ := br.Peek(sniffLen); detectType(h) != FileTypeTar {
+		_ = rep.readEntry(decompressedName(ar.name), br)
+		return nil
+	}
+	er := &eofReader{r: br}
+	tr := tar.NewReader(er)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) && er.eof {
+			// tar.Reader also ends at a block boundary with no end of archive blocks
+			return fmt.Errorf("%w: no tar end of archive marker", io.ErrUnexpectedEOF)
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err = rep.readEntry(hdr.Name, tr); err != nil {
+			// the stream is broken, the entries after it can not be reached
+			return nil
+		}
+	}
+	// the compression checksum comes after the tar end
+	_, err = io.Copy(io.Discard, br)
+	return err
+}
+
+// eofReader - a reader recording if its source reached the end, the tar end blocks are read before it
+type eofReader struct {
+	r   io.Reader
+	eof bool
+}
+
+func (e *eofReader) Read(p []byte) (int, error) {
+	n, err := e.r.Read(p)
+	if errors.Is(err, io.EOF) {
+		e.eof = true
+	}
+	return n, err
+}
+
+func verifyRar(rep *ArchiveReport, path string, cfg *archiveConfig) error {
+	rc, err := rardecode.OpenReader(path, rarOptions(cfg)...)
+	if err != nil {
+		return rarError(err)
+	}
+	defer rc.Close()
+	for {
+		hdr, err := rc.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return rarError(err)
+		}
+		if !hdr.Mode().IsRegular() {
+			continue
+		}
+		if err = rep.readEntry(hdr.Name, &rc.Reader); err != nil {
+			rep.Entries[len(rep.Entries)-1].Err = rarError(err)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package razutils
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestVerifyArchiveTruncatedTar - a plain tar cut at a block boundary is reported, not taken as complete
+func TestVerifyArchiveTruncatedTar(t *testing.T) {
+	d := t.TempDir()
+	src := filepath.Join(d, "a.tar")
+	writeTestTar(t, src, tarTestEntry{name: "a.txt", data: "first"}, tarTestEntry{name: "b.txt", data: "second"})
+	if _, err := VerifyArchive(src); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(src)
+	for _, size := range []int{512 * 2, 512 * 4, 512 * 5} { // after a, before the end blocks, between them
+		cut := filepath.Join(d, "cut.tar")
+		os.WriteFile(cut, data[:size], 0o644)
+		if _, err := VerifyArchive(cut); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("cut at %d: expected ErrUnexpectedEOF, got %v", size, err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package razutils
+
+import (
+	"context"
+	"io/fs"
+	"iter"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// FilterFunc - decide if a file found by the walk is wanted. d is the directory entry of path
+type FilterFunc func(path string, d fs.DirEntry) bool
+
+// ExtFilter - a filter keeping the files with one of the extensions (case ignored, with or without the dot)
+func ExtFilter(exts ...string) FilterFunc {
+	return func(path string, _ fs.DirEntry) bool { return matchExt(path, exts) }
+}
+
+// FileError - an error with the file it happened on
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e FileError) Error() string { return e.Path + ": " + e.Err.Error() }
+
+func (e FileError) Unwrap() error { return e.Err }
+
+// WalkFiles - iterate over the regular files under root (recursively) accepted by filter (nil accepts all):
+//
+//	for path, err := range WalkFiles(ctx, dir, ExtFilter("mkv", "mp4")) {...}
+//
+// a directory that can not be read is yielded with its error and the walk goes on.  Stopping the loop or cancelling
+// ctx stops the walk (the context error is yielded last).
+func WalkFiles(ctx context.Context, root string, filter FilterFunc) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		stopped := false
+		err := filepath.WalkDir(LongPath(root), func(path string, d fs.DirEntry, err error) error {
+			if cerr := ctx.Err(); cerr != nil {
+				return cerr
+			}
+			if err != nil {
+				if !yield(path, err) {
+					stopped = true
+					return filepath.SkipAll
+				}
+				if d != nil && d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() || (filter != nil && !filter(path, d)) {
+				return nil
+			}
+			if !yield(path, nil) {
+				stopped = true
+				return filepath.SkipAll
+			}
+			return nil
+		})
+		if err != nil && !stopped {
+			yield(root, err)
+		}
+	}
+}
+
+// ProcessFiles - call fn on every file under root accepted by filter, using up to workers goroutines.  Returns the
+// files fn failed on (and the directories that could not be read), sorted by path, and the context error if ctx
+// was cancelled (files not started by then are skipped).  A panic in fn is a *PanicError of its file.
+func ProcessFiles(ctx context.Context, root string, filter FilterFunc, workers int, fn func(path string) error) ([]FileError, error) {
+	var failed []FileError
+	var mu sync.Mutex
+	addErr := func(path string, err error) {
+		mu.Lock()
+		failed = append(failed, FileError{Path: path, Err: err})
+		mu.Unlock()
+	}
+	var g Group
+	g.SetLimit(max(workers, 1))
+	for path, err := range WalkFiles(ctx, root, filter) {
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			addErr(path, err)
+			continue
+		}
+		g.Go(func() error {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err := safeRun(func() error { return fn(path) }); err != nil {
+				addErr(path, err)
+			}
+			return nil
+		})
+	}
+	g.Wait()
+	slices.SortFunc(failed, func(a, b FileError) int { return strings.Compare(a.Path, b.Path) })
+	return failed, ctx.Err()
+}
@@ -0,0 +1,91 @@
+package razutils
+
+import (
+	"errors"
+	"sync"
+)
+
+/*
+WeightedChooser - a thread safe random picker where each item is picked proportionally to its weight (an item of
+weight 2 is picked twice as often as an item of weight 1).  When created without replacement each picked item is
+removed, so a sequence of picks samples the items without repetition.
+*/
+
+type weightedItem[T comparable] struct {
+	item   T
+	weight float64
+}
+
+type WeightedChooser[T comparable] struct {
+	items        []weightedItem[T]
+	total        float64
+	removePicked bool
+	mu           sync.Mutex
+}
+
+// NewWeightedChooser - create an empty chooser. withoutReplacement removes each picked item from the chooser.
+func NewWeightedChooser[T comparable](withoutReplacement bool) *WeightedChooser[T] {
+	return &WeightedChooser[T]{removePicked: withoutReplacement}
+}
+
+// Add - register an item with its weight. items with a weight of 0 or less are never picked and are ignored
+func (w *WeightedChooser[T]) Add(item T, weight float64) {
+	if weight <= 0 {
+		return
+	}
+	w.mu.Lock()
+	w.items = append(w.items, weightedItem[T]{item: item, weight: weight})
+	w.total += weight
+	w.mu.Unlock()
+}
+
+// Remove - remove (the first registration of) an item. returns false if it is not in the chooser
+func (w *WeightedChooser[T]) Remove(item T) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, it := range w.items {
+		if it.item == item {
+			w.removeLocked(i)
+			return true
+		}
+	}
+	return false
+}
+
+func (w *WeightedChooser[T]) removeLocked(i int) {
+	w.total -= w.items[i].weight
+	w.items = append(w.items[:i], w.items[i+1:]...)
+	if len(w.items) == 0 {
+		w.total = 0 // avoid floating point leftovers
+	}
+}
+
+// Pick - return a random item by the weights. Error is returned if there are no items
+func (w *WeightedChooser[T]) Pick() (T, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.items) == 0 {
+		var zero T
+		return zero, errors.New("no items to pick from")
+	}
+	r := RandFloat64() * w.total
+	i := 0
+	for ; i < len(w.items)-1; i++ {
+		r -= w.items[i].weight
+		if r < 0 {
+			break
+		}
+	}
+	item := w.items[i].item
+	if w.removePicked {
+		w.removeLocked(i)
+	}
+	return item, nil
+}
+
+// Len - return the number of items
+func (w *WeightedChooser[T]) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.items)
+}
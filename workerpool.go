@@ -0,0 +1,200 @@
+package razutils
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+/*
+WorkerPool - runs N goroutines taking items from a TypedQueue and calling a handler for each.
+A failing item is retried up to the retries count, a panic in the handler is recovered and counted as a failure.
+Stop lets the in-flight items finish (graceful), cancelling their context only if its own context is done first.
+*/
+
+// WorkerPoolStats - the pool counters
+type WorkerPoolStats struct {
+	InFlight  int64 // items being handled now
+	Completed int64 // items handled successfully
+	Failed    int64 // items that failed after all the retries
+	Retried   int64 // retry attempts made
+}
+
+// PoolEvent - published for each item the pool finished with (see SetEvents). Err is nil if the item was handled
+type PoolEvent[T any] struct {
+	Item     T
+	Err      error
+	Attempts int
+}
+
+// ErrPoolStopped - returned by Submit after Stop was called
+var ErrPoolStopped = errors.New("worker pool stopped")
+
+type WorkerPool[T comparable] struct {
+	queue     *TypedQueue[T]
+	handler   func(ctx context.Context, item T) error
+	workers   int
+	retries   int
+	onFailure func(item T, err error)
+	events    *Broadcaster[PoolEvent[T]]
+
+	inFlight                   Gauge
+	completed, failed, retried AtomicCounter
+
+	started    bool
+	stopped    bool
+	stopTaking context.CancelFunc // ends the workers loop
+	stopWork   context.CancelFunc // cancels the handlers context
+	wg         sync.WaitGroup
+	mu         sync.Mutex
+}
+
+// NewWorkerPool - create a pool of workers goroutines handling the items of queue (nil creates an internal queue,
+// fed with Submit).  The pool starts working on Start.
+func NewWorkerPool[T comparable](queue *TypedQueue[T], workers int, handler func(ctx context.Context, item T) error) *WorkerPool[T] {
+	if queue == nil {
+		queue = &TypedQueue[T]{}
+	}
+	return &WorkerPool[T]{queue: queue, handler: handler, workers: max(workers, 1)}
+}
+
+// SetRetries - set how many times a failed item is retried before counting it as failed (default 0). call before Start
+func (p *WorkerPool[T]) SetRetries(n int) {
+	p.retries = max(n, 0)
+}
+
+// OnFailure - set a callback for items that failed after all the retries. call before Start
+func (p *WorkerPool[T]) OnFailure(fn func(item T, err error)) {
+	p.onFailure = fn
+}
+
+// SetEvents - publish a PoolEvent for every finished item on b, e.g. for progress displays. call before Start
+func (p *WorkerPool[T]) SetEvents(b *Broadcaster[PoolEvent[T]]) {
+	p.events = b
+}
+
+// Queue - return the queue the pool consumes
+func (p *WorkerPool[T]) Queue() *TypedQueue[T] {
+	return p.queue
+}
+
+// Submit - add an item to the pool queue
+func (p *WorkerPool[T]) Submit(item T) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return ErrPoolStopped
+	}
+	p.queue.Push(item)
+	return nil
+}
+
+// Start - start the workers. ctx is passed to the handlers, cancelling it stops the pool without waiting.
+func (p *WorkerPool[T]) Start(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started {
+		return
+	}
+	p.started = true
+	workCtx, stopWork := context.WithCancel(ctx)
+	takeCtx, stopTaking := context.WithCancel(workCtx)
+	p.stopWork, p.stopTaking = stopWork, stopTaking
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for {
+				item, err := p.queue.PopWait(takeCtx)
+				if err != nil {
+					return
+				}
+				p.process(workCtx, item)
+			}
+		}()
+	}
+}
+
+// process - handle a single item with the retries
+func (p *WorkerPool[T]) process(ctx context.Context, item T) {
+	p.inFlight.Inc()
+	defer p.inFlight.Dec()
+	var err error
+	attempts := 0
+	for attempts <= p.retries {
+		if attempts > 0 {
+			if ctx.Err() != nil {
+				break
+			}
+			p.retried.Inc()
+		}
+		attempts++
+		if err = p.safeCall(ctx, item); err == nil {
+			break
+		}
+	}
+	if err == nil {
+		p.completed.Inc()
+	} else {
+		p.failed.Inc()
+		if p.onFailure != nil {
+			p.onFailure(item, err)
+		}
+	}
+	if p.events != nil {
+		p.events.Publish(PoolEvent[T]{Item: item, Err: err, Attempts: attempts})
+	}
+}
+
+// safeCall - call the handler converting a panic into a *PanicError
+func (p *WorkerPool[T]) safeCall(ctx context.Context, item T) (err error) {
+	defer recoverError(&err)
+	return p.handler(ctx, item)
+}
+
+// Stop - stop taking items and wait for the in-flight ones to finish.  If ctx is done first the handlers context is
+// cancelled and the context error is returned.  items left in the queue stay there.
+func (p *WorkerPool[T]) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	p.stopped = true
+	started := p.started
+	p.mu.Unlock()
+	if !started {
+		return nil
+	}
+	p.stopTaking()
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		p.stopWork()
+		return nil
+	case <-ctx.Done():
+		p.stopWork()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Stats - return the pool counters
+func (p *WorkerPool[T]) Stats() WorkerPoolStats {
+	return WorkerPoolStats{
+		InFlight:  p.inFlight.Load(),
+		Completed: p.completed.Load(),
+		Failed:    p.failed.Load(),
+		Retried:   p.retried.Load(),
+	}
+}
+
+// RegisterMetrics - report the pool counters through a registry as prefix.in_flight, prefix.completed, prefix.failed
+// and prefix.retried, and its queue under prefix.queue
+func (p *WorkerPool[T]) RegisterMetrics(r *Registry, prefix string) {
+	r.Register(prefix+".in_flight", &p.inFlight)
+	r.Register(prefix+".completed", &p.completed)
+	r.Register(prefix+".failed", &p.failed)
+	r.Register(prefix+".retried", &p.retried)
+	p.queue.RegisterMetrics(r, prefix+".queue")
+}
@@ -0,0 +1,6 @@
+package razutils
+
+import "errors"
+
+// ErrXattrUnsupported - returned by the xattr helpers on platforms without extended attributes support
+var ErrXattrUnsupported = errors.New("extended attributes not supported on this platform")
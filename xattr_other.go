@@ -0,0 +1,23 @@
+//go:build !linux && !darwin
+
+package razutils
+
+// GetXattr - extended attributes are not supported on this platform, ErrXattrUnsupported is returned
+func GetXattr(path string, name string) ([]byte, error) {
+	return nil, ErrXattrUnsupported
+}
+
+// SetXattr - extended attributes are not supported on this platform, ErrXattrUnsupported is returned
+func SetXattr(path string, name string, value []byte) error {
+	return ErrXattrUnsupported
+}
+
+// RemoveXattr - extended attributes are not supported on this platform, ErrXattrUnsupported is returned
+func RemoveXattr(path string, name string) error {
+	return ErrXattrUnsupported
+}
+
+// ListXattr - extended attributes are not supported on this platform, ErrXattrUnsupported is returned
+func ListXattr(path string) ([]string, error) {
+	return nil, ErrXattrUnsupported
+}
@@ -0,0 +1,78 @@
+//go:build linux || darwin
+
+package razutils
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// xattrName - on linux user attributes must live in the "user." namespace, add it when no namespace is given
+func xattrName(name string) string {
+	if runtime.GOOS == "linux" && !strings.Contains(name, ".") {
+		return "user." + name
+	}
+	return name
+}
+
+// GetXattr - read an extended attribute of a file.  on linux a name without a namespace gets "user." prepended.
+func GetXattr(path string, name string) ([]byte, error) {
+	name = xattrName(name)
+	for {
+		size, err := unix.Getxattr(path, name, nil)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size)
+		n, err := unix.Getxattr(path, name, buf)
+		if errors.Is(err, unix.ERANGE) {
+			continue // the value grew between the calls
+		}
+		if err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	}
+}
+
+// SetXattr - set (create or replace) an extended attribute of a file
+func SetXattr(path string, name string, value []byte) error {
+	return unix.Setxattr(path, xattrName(name), value, 0)
+}
+
+// RemoveXattr - remove an extended attribute of a file
+func RemoveXattr(path string, name string) error {
+	return unix.Removexattr(path, xattrName(name))
+}
+
+// ListXattr - list the names of the extended attributes of a file
+func ListXattr(path string) ([]string, error) {
+	for {
+		size, err := unix.Listxattr(path, nil)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return []string{}, nil
+		}
+		buf := make([]byte, size)
+		n, err := unix.Listxattr(path, buf)
+		if errors.Is(err, unix.ERANGE) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, b := range bytes.Split(buf[:n], []byte{0}) {
+			if len(b) > 0 {
+				names = append(names, string(b))
+			}
+		}
+		return names, nil
+	}
+}
@@ -0,0 +1,19 @@
+package razutils
+
+import (
+	"io"
+
+	"github.com/ulikunitz/xz"
+)
+
+// XzExtract - expand a .xz file into the original file. Source is the xz file path, dest is what the result
+// filename should be
+func XzExtract(source string, dest string, opts ...ArchiveOption) error {
+	return extractSingle(source, dest, func(r io.Reader) (io.ReadCloser, error) {
+		xr, err := xz.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(xr), nil
+	}, opts)
+}
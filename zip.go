@@ -0,0 +1,143 @@
+package razutils
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// ZipCreate - create a zip at dest holding the given files and directories (recursively, each under its base name),
+// keeping the file modes and modification times.  WithPassword encrypts the files with AES-256.
+func ZipCreate(dest string, paths []string, opts ...ArchiveOption) error {
+	cfg := archiveOptions(opts)
+	files, err := collectArchiveFiles(paths)
+	if err != nil {
+		return err
+	}
+	fout, err := createArchiveFile(dest, cfg)
+	if err != nil {
+		return err
+	}
+	defer fout.Close()
+	zw := zip.NewWriter(fout)
+	zw.RegisterCompressor(zip.Deflate, func(w io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(w, cfg.level)
+	})
+	if cfg.password != "" {
+		zw.RegisterCompressor(zipMethodAES, zipAESCompressor(cfg.password, cfg.level))
+	}
+	for _, f := range files {
+		if err = zipAddFile(zw, f, cfg); err != nil {
+			return err
+		}
+	}
+	if err = zw.Close(); err != nil {
+		return err
+	}
+	return fout.Close()
+}
+
+// zipAddFile - add a single file or directory entry
+func zipAddFile(zw *zip.Writer, f archiveFile, cfg archiveConfig) error {
+	hdr, err := zip.FileInfoHeader(f.info)
+	if err != nil {
+		return err
+	}
+	hdr.Name = f.name
+	switch {
+	case f.info.IsDir():
+		hdr.Name += "/"
+		hdr.Method = zip.Store
+	case cfg.password != "":
+		zipAESHeader(hdr, cfg.level)
+	case cfg.level == 0:
+		hdr.Method = zip.Store
+	default:
+		hdr.Method = zip.Deflate
+	}
+	w, err := zw.CreateHeader(hdr)
+	if err != nil || f.info.IsDir() {
+		return err
+	}
+	if f.info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(f.path)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, target)
+		return err
+	}
+	r, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	_, err = io.Copy(w, pausable(r))
+	return err
+}
+
+// ZipExtract - extract the zip src into destDir, restoring the directory structure, file modes and modification
+// times.  Entries with absolute paths or going out of destDir (zip slip) fail the extraction with ErrUnsafePath
+// (see WithLenientPaths).  Encrypted entries need WithPassword, or fail with ErrPasswordRequired.
+func ZipExtract(src string, destDir string, opts ...ArchiveOption) error {
+	fin, err := openArchive(src)
+	if err != nil {
+		return err
+	}
+	defer fin.Close()
+	zr, err := zip.NewReader(fin, fin.Size())
+	if err != nil {
+		return err
+	}
+	cfg := archiveOptions(opts)
+	if cfg.progress != nil {
+		for _, f := range zr.File {
+			if cfg.wants(f.Name, f.Mode().IsRegular()) {
+				cfg.progress.total += int64(f.UncompressedSize64)
+			}
+		}
+	}
+	dt := dirTimes{}
+	for _, f := range zr.File {
+		mode := f.Mode()
+		if !cfg.wants(f.Name, mode.IsRegular()) {
+			continue
+		}
+		target, err := cfg.target(destDir, f.Name)
+		if err != nil {
+			return err
+		}
+		switch {
+		case mode.IsDir():
+			if err = os.MkdirAll(LongPath(target), 0o755); err != nil {
+				return err
+			}
+			// keep the directory writable for the owner, files are still to be written into it
+			if err = os.Chmod(LongPath(target), mode.Perm()|0o700); err != nil {
+				return err
+			}
+			dt[target] = f.Modified
+		case mode&fs.ModeSymlink != 0:
+			// symlinks are not restored from zips, they are a way out of destDir
+			continue
+		default:
+			cfg.progress.entry(f.Name)
+			if err = zipExtractFile(f, target, &cfg); err != nil {
+				return err
+			}
+		}
+	}
+	return dt.apply()
+}
+
+// zipExtractFile - write a single file entry
+func zipExtractFile(f *zip.File, target string, cfg *archiveConfig) error {
+	r, err := zipOpen(f, cfg.password)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return writeEntryFile(target, cfg.progress.reader(r), f.Mode(), f.Modified)
+}
@@ -0,0 +1,390 @@
+package razutils
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+/*
+Encrypted zip entries: the legacy ZipCrypto (PKWARE) encryption, which is weak but still what most tools write by
+default, and the WinZip AES encryption (compression method 99) which 7-Zip, WinZip and WinRAR write when asked
+for AES.  Both are read, created archives are always AES-256.
+*/
+
+var (
+	// ErrPasswordRequired - an encrypted archive entry was found and no password was given (see WithPassword)
+	ErrPasswordRequired = errors.New("archive entry is encrypted, a password is required")
+	// ErrBadPassword - the password does not match the encrypted archive entry
+	ErrBadPassword = errors.New("wrong password for archive entry")
+)
+
+// WithPassword - the password of encrypted archives: ZipExtract decrypts the encrypted entries (AES or ZipCrypto),
+// ZipCreate encrypts all the files with AES-256 (the names of the entries are not encrypted), RarExtract and
+// RarList decrypt encrypted rars.
+func WithPassword(password string) ArchiveOption {
+	return func(c *archiveConfig) { c.password = password }
+}
+
+const (
+	zipMethodAES  = 99
+	zipAESExtraID = 0x9901
+	zipAESAuthLen = 10
+)
+
+// zipOpen - open the entry f for reading, decrypting it if needed
+func zipOpen(f *zip.File, password string) (io.ReadCloser, error) {
+	if f.Flags&0x1 == 0 {
+		return f.Open()
+	}
+	if password == "" {
+		return nil, fmt.Errorf("%w: %s", ErrPasswordRequired, f.Name)
+	}
+	raw, err := f.OpenRaw()
+	if err != nil {
+		return nil, err
+	}
+	method, checkCRC := f.Method, true
+	var r io.Reader
+	if f.Method == zipMethodAES {
+		var ae2 bool
+		r, method, ae2, err = zipAESReader(raw, f, password)
+		// AE-2 leaves the CRC out, the authentication code is checked instead
+		checkCRC = !ae2
+	} else {
+		r, err = zipCryptoReader(raw, f, password)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rc io.ReadCloser
+	switch method {
+	case zip.Store:
+		rc = io.NopCloser(r)
+	case zip.Deflate:
+		rc = flate.NewReader(r)
+	default:
+		return nil, fmt.Errorf("%w: %s", zip.ErrAlgorithm, f.Name)
+	}
+	if !checkCRC {
+		return rc, nil
+	}
+	// the ZipCrypto password check is a single byte, 1 wrong password in 256 passes it and the data is then garbage
+	return &crcReader{rc: rc, hash: crc32.NewIEEE(), want: f.CRC32, zipCrypto: f.Method != zipMethodAES}, nil
+}
+
+// crcReader - check the CRC of the data at its end, zip.File.Open does it for the entries it decompresses
+type crcReader struct {
+	rc        io.ReadCloser
+	hash      hash.Hash32
+	want      uint32
+	zipCrypto bool // bad data is most likely a wrong password
+}
+
+func (cr *crcReader) Read(b []byte) (int, error) {
+	n, err := cr.rc.Read(b)
+	cr.hash.Write(b[:n])
+	if errors.Is(err, io.EOF) && cr.hash.Sum32() != cr.want {
+		err = zip.ErrChecksum
+	}
+	if cr.zipCrypto && err != nil && !errors.Is(err, io.EOF) {
+		err = fmt.Errorf("%w (or a damaged entry): %w", ErrBadPassword, err)
+	}
+	return n, err
+}
+
+func (cr *crcReader) Close() error {
+	return cr.rc.Close()
+}
+
+// zipCryptoKeys - the state of the ZipCrypto cipher
+type zipCryptoKeys [3]uint32
+
+func newZipCryptoKeys(password string) *zipCryptoKeys {
+	k := &zipCryptoKeys{0x12345678, 0x23456789, 0x34567890}
+	for i := 0; i < len(password); i++ {
+		k.update(password[i])
+	}
+	return k
+}
+
+func crc32Byte(crc uint32, b byte) uint32 {
+	return crc32.IEEETable[byte(crc)^b] ^ crc>>8
+}
+
+func (k *zipCryptoKeys) update(b byte) {
+	k[0] = crc32Byte(k[0], b)
+	k[1] = (k[1]+k[0]&0xff)*134775813 + 1
+	k[2] = crc32Byte(k[2], byte(k[1]>>24))
+}
+
+func (k *zipCryptoKeys) decrypt(b []byte) {
+	for i, c := range b {
+		t := k[2] | 2
+		p := c ^ byte(t*(t^1)>>8)
+		k.update(p)
+		b[i] = p
+	}
+}
+
+// zipCryptoReader - decrypt a ZipCrypto entry.  the last byte of its 12 bytes header is a check byte for the
+// password, the high byte of the CRC or, when the entry has a data descriptor, of the time.
+func zipCryptoReader(raw io.Reader, f *zip.File, password string) (io.Reader, error) {
+	k := newZipCryptoKeys(password)
+	hdr := make([]byte, 12)
+	if _, err := io.ReadFull(raw, hdr); err != nil {
+		return nil, err
+	}
+	k.decrypt(hdr)
+	if c := hdr[11]; c != byte(f.CRC32>>24) && c != byte(f.ModifiedTime>>8) {
+		return nil, fmt.Errorf("%w: %s", ErrBadPassword, f.Name)
+	}
+	return &zipCryptoDecrypter{r: raw, k: k}, nil
+}
+
+type zipCryptoDecrypter struct {
+	r io.Reader
+	k *zipCryptoKeys
+}
+
+func (d *zipCryptoDecrypter) Read(b []byte) (int, error) {
+	n, err := d.r.Read(b)
+	d.k.decrypt(b[:n])
+	return n, err
+}
+
+// zipAESExtra - the WinZip AES extra field: the version (1 for AE-1, 2 for AE-2), the key strength (1, 2, 3 for
+// 128, 192, 256 bits) and the compression method of the data
+func zipAESExtra(extra []byte) (version uint16, strength byte, method uint16, ok bool) {
+	for len(extra) >= 4 {
+		id, size := binary.LittleEndian.Uint16(extra), int(binary.LittleEndian.Uint16(extra[2:]))
+		extra = extra[4:]
+		if size > len(extra) {
+			break
+		}
+		if id == zipAESExtraID && size >= 7 {
+			return binary.LittleEndian.Uint16(extra), extra[4], binary.LittleEndian.Uint16(extra[5:]), true
+		}
+		extra = extra[size:]
+	}
+	return 0, 0, 0, false
+}
+
+// zipAESKeys - derive the encryption and authentication keys and the password verifier from the password
+func zipAESKeys(password string, salt []byte, keyLen int) (enc, auth, verify []byte, err error) {
+	k, err := pbkdf2.Key(sha1.New, password, salt, 1000, 2*keyLen+2)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return k[:keyLen], k[keyLen : 2*keyLen], k[2*keyLen:], nil
+}
+
+// zipAESReader - decrypt a WinZip AES entry: salt, password verifier, the encrypted data and the authentication code
+// (HMAC-SHA1 of the encrypted data) checked at its end.  the compression method of the data is returned.
+func zipAESReader(raw io.Reader, f *zip.File, password string) (io.Reader, uint16, bool, error) {
+	version, strength, method, ok := zipAESExtra(f.Extra)
+	if !ok || strength < 1 || strength > 3 {
+		return nil, 0, false, fmt.Errorf("%w: bad AES header in %s", zip.ErrFormat, f.Name)
+	}
+	keyLen := 8 * (int(strength) + 1)
+	saltLen := keyLen / 2
+	size := int64(f.CompressedSize64) - int64(saltLen) - 2 - zipAESAuthLen
+	if size < 0 {
+		return nil, 0, false, fmt.Errorf("%w: %s", zip.ErrFormat, f.Name)
+	}
+	head := make([]byte, saltLen+2)
+	if _, err := io.ReadFull(raw, head); err != nil {
+		return nil, 0, false, err
+	}
+	enc, auth, verify, err := zipAESKeys(password, head[:saltLen], keyLen)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if !bytes.Equal(verify, head[saltLen:]) {
+		return nil, 0, false, fmt.Errorf("%w: %s", ErrBadPassword, f.Name)
+	}
+	block, err := aes.NewCipher(enc)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	return &zipAESDecrypter{
+		r:    io.LimitReader(raw, size),
+		raw:  raw,
+		ctr:  newWinZipCTR(block),
+		mac:  hmac.New(sha1.New, auth),
+		name: f.Name,
+	}, method, version == 2, nil
+}
+
+type zipAESDecrypter struct {
+	r    io.Reader // the encrypted data
+	raw  io.Reader // the whole entry, the authentication code follows the data
+	ctr  *winZipCTR
+	mac  hash.Hash
+	name string
+}
+
+func (d *zipAESDecrypter) Read(b []byte) (int, error) {
+	n, err := d.r.Read(b)
+	d.mac.Write(b[:n])
+	d.ctr.XORKeyStream(b[:n], b[:n])
+	if errors.Is(err, io.EOF) {
+		code := make([]byte, zipAESAuthLen)
+		if _, rerr := io.ReadFull(d.raw, code); rerr != nil {
+			return n, rerr
+		}
+		if !hmac.Equal(code, d.mac.Sum(nil)[:zipAESAuthLen]) {
+			return n, fmt.Errorf("%w: %s authentication failed", zip.ErrChecksum, d.name)
+		}
+	}
+	return n, err
+}
+
+// winZipCTR - AES in counter mode as WinZip does it, with a little endian counter starting at 1 (cipher.NewCTR
+// counts big endian)
+type winZipCTR struct {
+	block cipher.Block
+	ctr   [aes.BlockSize]byte
+	ks    [aes.BlockSize]byte
+	used  int
+}
+
+func newWinZipCTR(block cipher.Block) *winZipCTR {
+	return &winZipCTR{block: block, used: aes.BlockSize}
+}
+
+func (c *winZipCTR) XORKeyStream(dst, src []byte) {
+	for i := range src {
+		if c.used == aes.BlockSize {
+			for j := range c.ctr {
+				c.ctr[j]++
+				if c.ctr[j] != 0 {
+					break
+				}
+			}
+			c.block.Encrypt(c.ks[:], c.ctr[:])
+			c.used = 0
+		}
+		dst[i] = src[i] ^ c.ks[c.used]
+		c.used++
+	}
+}
+
+// zipAESHeader - set up hdr for an AES encrypted entry, keeping the CRC (AE-1) so damaged data is detected after
+// decompression as well
+func zipAESHeader(hdr *zip.FileHeader, level int) {
+	method := zip.Deflate
+	if level == 0 {
+		method = zip.Store
+	}
+	extra := make([]byte, 11)
+	binary.LittleEndian.PutUint16(extra, zipAESExtraID)
+	binary.LittleEndian.PutUint16(extra[2:], 7)
+	binary.LittleEndian.PutUint16(extra[4:], 1)
+	copy(extra[6:], "AE")
+	extra[8] = 3 // 256 bits
+	binary.LittleEndian.PutUint16(extra[9:], method)
+	hdr.Extra = append(hdr.Extra, extra...)
+	hdr.Method = zipMethodAES
+	hdr.Flags |= 0x1
+}
+
+// zipAESWriter - encrypt an entry with AES-256, registered as the compressor of method 99 so the zip writer still
+// takes care of the sizes, CRC and data descriptor.  the data is compressed by level before it is encrypted.
+type zipAESWriter struct {
+	w    io.Writer
+	head []byte         // salt and password verifier, written with the data (the entry header is not written yet)
+	comp io.WriteCloser // compresses into the encrypter, nil to store
+	ctr  *winZipCTR
+	mac  hash.Hash
+	buf  []byte
+}
+
+// zipAESCompressor - the compressor for method 99 with the given password and compression level (0 stores)
+func zipAESCompressor(password string, level int) zip.Compressor {
+	return func(w io.Writer) (io.WriteCloser, error) {
+		const keyLen = 32
+		salt := make([]byte, keyLen/2)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		enc, auth, verify, err := zipAESKeys(password, salt, keyLen)
+		if err != nil {
+			return nil, err
+		}
+		block, err := aes.NewCipher(enc)
+		if err != nil {
+			return nil, err
+		}
+		aw := &zipAESWriter{w: w, head: append(salt, verify...), ctr: newWinZipCTR(block), mac: hmac.New(sha1.New, auth)}
+		if level != 0 {
+			if aw.comp, err = flate.NewWriter(encryptWriter{aw}, level); err != nil {
+				return nil, err
+			}
+		}
+		return aw, nil
+	}
+}
+
+func (aw *zipAESWriter) Write(b []byte) (int, error) {
+	if aw.comp != nil {
+		return aw.comp.Write(b)
+	}
+	return aw.encrypt(b)
+}
+
+// writeHead - write the salt and password verifier before the first data
+func (aw *zipAESWriter) writeHead() error {
+	if aw.head == nil {
+		return nil
+	}
+	_, err := aw.w.Write(aw.head)
+	aw.head = nil
+	return err
+}
+
+// encrypt - encrypt b into the entry
+func (aw *zipAESWriter) encrypt(b []byte) (int, error) {
+	if err := aw.writeHead(); err != nil {
+		return 0, err
+	}
+	aw.buf = append(aw.buf[:0], b...)
+	aw.ctr.XORKeyStream(aw.buf, aw.buf)
+	aw.mac.Write(aw.buf)
+	return aw.w.Write(aw.buf)
+}
+
+// Close - flush the compressor and write the authentication code
+func (aw *zipAESWriter) Close() error {
+	if aw.comp != nil {
+		if err := aw.comp.Close(); err != nil {
+			return err
+		}
+	}
+	if err := aw.writeHead(); err != nil {
+		return err
+	}
+	_, err := aw.w.Write(aw.mac.Sum(nil)[:zipAESAuthLen])
+	return err
+}
+
+// encryptWriter - the compressor output, encrypted
+type encryptWriter struct {
+	aw *zipAESWriter
+}
+
+func (ew encryptWriter) Write(b []byte) (int, error) {
+	return ew.aw.encrypt(b)
+}
@@ -0,0 +1,63 @@
+package razutils
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"testing"
+)
+
+// TestZipCryptoWrongPasswordPassingCheck - a wrong password that passes the single byte ZipCrypto check is reported
+// as ErrBadPassword once the data does not match
+func TestZipCryptoWrongPasswordPassingCheck(t *testing.T) {
+	data := bytes.Repeat([]byte("zip crypto "), 1000)
+	crc := crc32.ChecksumIEEE(data)
+	plain := append(make([]byte, 12), data...)
+	plain[11] = byte(crc >> 24)
+	k := newZipCryptoKeys("secret")
+	body := make([]byte, len(plain))
+	for i, c := range plain {
+		x := k[2] | 2
+		body[i] = c ^ byte(x*(x^1)>>8)
+		k.update(c)
+	}
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	w, err := zw.CreateRaw(&zip.FileHeader{Name: "x.txt", Method: zip.Store, Flags: 0x1, CRC32: crc,
+		CompressedSize64: uint64(len(body)), UncompressedSize64: uint64(len(data))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(body)
+	zw.Close()
+	zr, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := func(rc io.ReadCloser) error {
+		defer rc.Close()
+		_, err := io.Copy(io.Discard, rc)
+		return err
+	}
+	rc, err := zipOpen(zr.File[0], "secret")
+	if err != nil || read(rc) != nil {
+		t.Fatal("the right password failed", err)
+	}
+	for i := 0; i < 10000; i++ {
+		rc, err := zipOpen(zr.File[0], fmt.Sprint("wrong", i))
+		if errors.Is(err, ErrBadPassword) {
+			continue // the usual case, caught by the check byte
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = read(rc); !errors.Is(err, ErrBadPassword) {
+			t.Fatalf("wrong%d passed the check byte: expected ErrBadPassword, got %v", i, err)
+		}
+		return
+	}
+	t.Skip("no wrong password passed the check byte")
+}
@@ -0,0 +1,48 @@
+package razutils
+
+import (
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ZstdExtract - expand a .zst file into the original file. Source is the zst file path, dest is what the result
+// filename should be
+func ZstdExtract(source string, dest string, opts ...ArchiveOption) error {
+	return extractSingle(source, dest, func(r io.Reader) (io.ReadCloser, error) {
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	}, opts)
+}
+
+// ZstdCompress - compress the source file into a .zst file at dest (the source is not removed).  WithCompressionLevel
+// sets the level, on the gzip 0-9 scale.
+func ZstdCompress(source string, dest string, opts ...ArchiveOption) error {
+	cfg := archiveOptions(opts)
+	r, err := os.Open(LongPath(source))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	fout, err := os.Create(LongPath(dest))
+	if err != nil {
+		return err
+	}
+	defer fout.Close()
+	writer, err := zstd.NewWriter(fout, zstd.WithEncoderLevel(zstdLevel(cfg.level)))
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(writer, pausable(r)); err != nil {
+		writer.Close()
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+	return fout.Close()
+}
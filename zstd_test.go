@@ -0,0 +1,36 @@
+package razutils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestZstdCompressLevel - the compression level option is honored and the result extracts back
+func TestZstdCompressLevel(t *testing.T) {
+	d := t.TempDir()
+	src := filepath.Join(d, "data.txt")
+	data := bytes.Repeat([]byte("the quick brown fox jumps over the lazy dog, "), 20000)
+	os.WriteFile(src, data, 0o644)
+	sizes := map[int]int64{}
+	for _, level := range []int{1, 9} {
+		dest := filepath.Join(d, "data.zst")
+		if err := ZstdCompress(src, dest, WithCompressionLevel(level)); err != nil {
+			t.Fatal(err)
+		}
+		fi, _ := os.Stat(dest)
+		sizes[level] = fi.Size()
+		out := filepath.Join(d, "out.txt")
+		if err := ZstdExtract(dest, out); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := os.ReadFile(out); !bytes.Equal(got, data) {
+			t.Fatalf("level %d: content differs", level)
+		}
+		os.Remove(out)
+	}
+	if sizes[9] > sizes[1] {
+		t.Fatalf("level 9 (%d bytes) larger than level 1 (%d bytes)", sizes[9], sizes[1])
+	}
+}